@@ -0,0 +1,228 @@
+package mapjitsu
+
+import (
+	"context"
+	"fmt"
+	"io"
+)
+
+// RecordReader reads records one at a time, returning io.EOF once there
+// are no more records.
+type RecordReader interface {
+	Read() (interface{}, error)
+}
+
+// RecordWriter writes records one at a time. Writers which buffer output
+// may also implement Flush() error, which Runner calls once all records
+// have been written.
+type RecordWriter interface {
+	Write(record interface{}) error
+}
+
+type flusher interface {
+	Flush() error
+}
+
+// OffsetReader is an optional interface implemented by RecordReaders which
+// know the position of each record in their input. Offset returns the
+// position, in bytes, of the record last read or failing to be read, which
+// Runner includes in any RecordError.
+type OffsetReader interface {
+	Offset() int64
+}
+
+// BadRecordPolicy determines how a Runner handles a record which fails
+// to map.
+type BadRecordPolicy int
+
+const (
+	// FailOnBadRecord stops the run, returning the *RecordError.
+	FailOnBadRecord BadRecordPolicy = iota
+	// SkipBadRecord leaves the record out of the output and continues.
+	SkipBadRecord
+	// QuarantineBadRecord writes the input record to the Runner's
+	// Quarantine writer and continues.
+	QuarantineBadRecord
+)
+
+// RecordError is an error mapping a record, numbered from 1 in the order
+// records were read. Offset is the position of the record in the input if
+// the Reader is an OffsetReader, otherwise -1.
+type RecordError struct {
+	Record int
+	Offset int64
+	Err    error
+}
+
+func (e *RecordError) Error() string {
+	if e.Offset >= 0 {
+		return fmt.Sprintf("record %d at offset %d: %v", e.Record, e.Offset, e.Err)
+	}
+	return fmt.Sprintf("record %d: %v", e.Record, e.Err)
+}
+
+func (e *RecordError) Unwrap() error {
+	return e.Err
+}
+
+// BadRecordError is returned by a RecordReader for a record which was
+// read but is invalid, e.g. a CSV row with the wrong number of fields.
+// Runner handles the Record with its BadRecordPolicy, as for a record
+// which fails to map, and continues reading.
+type BadRecordError struct {
+	Record interface{}
+	Err    error
+}
+
+func (e *BadRecordError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *BadRecordError) Unwrap() error {
+	return e.Err
+}
+
+// RunResult summarises a run.
+type RunResult struct {
+	Read        int
+	Written     int
+	Skipped     int
+	Quarantined int
+}
+
+// Runner streams records from a Reader, maps each to a new output record
+// and writes it to a Writer, holding only one record in memory at a time.
+//
+// Records are mapped by the Template if set, otherwise by the Definition
+// returned from Definition for each record.
+type Runner struct {
+	Reader     RecordReader
+	Writer     RecordWriter
+	Template   *Template
+	Definition func(input interface{}, output interface{}) Definition
+	NewOutput  func(input interface{}) interface{} // returns a new empty output record
+
+	OnBadRecord BadRecordPolicy
+	Quarantine  RecordWriter // required for QuarantineBadRecord
+
+	// OnRecordError is optionally called with the error for each
+	// record skipped or quarantined.
+	OnRecordError func(err *RecordError)
+
+	// Workers optionally maps records in parallel using this many
+	// goroutines. Records are still read and written one at a time, in
+	// order, so Reader, Writer and OnRecordError need not be safe for
+	// concurrent use but the Template or Definition must be.
+	Workers int
+	// InFlight limits the number of records read but not yet written when
+	// mapping in parallel, defaulting to twice Workers.
+	InFlight int
+}
+
+// Run maps every record, stopping at the first error reading or writing
+// records or, with FailOnBadRecord, mapping them or reading a
+// *BadRecordError.
+func (r Runner) Run(ctx context.Context) (RunResult, error) {
+	var result RunResult
+	if r.Template == nil && r.Definition == nil {
+		return result, fmt.Errorf("either a Template or Definition must be provided")
+	}
+	if r.OnBadRecord == QuarantineBadRecord && r.Quarantine == nil {
+		return result, fmt.Errorf("a Quarantine writer must be provided to quarantine bad records")
+	}
+	if r.Workers > 1 {
+		return r.runParallel(ctx)
+	}
+	for {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+		input, err := r.Reader.Read()
+		if err == io.EOF {
+			break
+		}
+		offset := r.offset()
+		bad, isBad := err.(*BadRecordError)
+		if err != nil && !isBad {
+			return result, &RecordError{Record: result.Read + 1, Offset: offset, Err: err}
+		}
+		result.Read++
+
+		var output interface{}
+		if isBad {
+			input, err = bad.Record, bad.Err
+		} else {
+			output, err = r.mapRecord(ctx, input)
+		}
+		err = r.handle(&result, input, offset, output, err)
+		if err != nil {
+			return result, err
+		}
+	}
+	return result, r.flush()
+}
+
+// mapRecord returns the output record mapped from input
+func (r Runner) mapRecord(ctx context.Context, input interface{}) (interface{}, error) {
+	var output interface{}
+	if r.NewOutput != nil {
+		output = r.NewOutput(input)
+	}
+	if r.Template != nil {
+		return output, r.Template.ExecuteContext(ctx, input, output)
+	}
+	return output, r.Definition(input, output).ApplyContext(ctx)
+}
+
+// offset returns the position of the record last read, or -1 if unknown
+func (r Runner) offset() int64 {
+	if o, ok := r.Reader.(OffsetReader); ok {
+		return o.Offset()
+	}
+	return -1
+}
+
+// handle writes the output record for a record mapped without error,
+// otherwise applying the BadRecordPolicy
+func (r Runner) handle(result *RunResult, input interface{}, offset int64, output interface{}, err error) error {
+	if err != nil {
+		return r.badRecord(result, input, &RecordError{Record: result.Read, Offset: offset, Err: err})
+	}
+	err = r.Writer.Write(output)
+	if err != nil {
+		return &RecordError{Record: result.Read, Offset: offset, Err: fmt.Errorf("failed to write record %v", err)}
+	}
+	result.Written++
+	return nil
+}
+
+// badRecord applies the BadRecordPolicy to a record which failed to map
+func (r Runner) badRecord(result *RunResult, input interface{}, recordError *RecordError) error {
+	switch r.OnBadRecord {
+	case SkipBadRecord:
+		result.Skipped++
+	case QuarantineBadRecord:
+		err := r.Quarantine.Write(input)
+		if err != nil {
+			return &RecordError{Record: recordError.Record, Offset: recordError.Offset, Err: fmt.Errorf("failed to quarantine record %v", err)}
+		}
+		result.Quarantined++
+	default:
+		return recordError
+	}
+	if r.OnRecordError != nil {
+		r.OnRecordError(recordError)
+	}
+	return nil
+}
+
+func (r Runner) flush() error {
+	for _, w := range []RecordWriter{r.Writer, r.Quarantine} {
+		if f, ok := w.(flusher); ok {
+			if err := f.Flush(); err != nil {
+				return fmt.Errorf("failed to flush records %v", err)
+			}
+		}
+	}
+	return nil
+}
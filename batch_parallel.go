@@ -0,0 +1,118 @@
+package mapjitsu
+
+import (
+	"context"
+	"io"
+	"sync"
+)
+
+type parallelRecord struct {
+	seq     int
+	offset  int64
+	input   interface{}
+	output  interface{}
+	err     error // error mapping the record, or reading a bad record
+	readErr error
+}
+
+// runParallel maps records using Workers goroutines, reassembling the
+// output records in the order they were read before writing them
+func (r Runner) runParallel(parent context.Context) (RunResult, error) {
+	var result RunResult
+
+	ctx, cancel := context.WithCancel(parent)
+	defer cancel()
+
+	inFlight := r.InFlight
+	if inFlight < 1 {
+		inFlight = 2 * r.Workers
+	}
+	sem := make(chan struct{}, inFlight) // records read but not yet written
+	jobs := make(chan parallelRecord)
+	results := make(chan parallelRecord, inFlight)
+
+	// records are read by a single goroutine, only once there is room
+	go func() {
+		defer close(jobs)
+		for seq := 1; ; seq++ {
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+			input, err := r.Reader.Read()
+			if err == io.EOF {
+				return
+			}
+			job := parallelRecord{seq: seq, offset: r.offset(), input: input, readErr: err}
+			if bad, ok := err.(*BadRecordError); ok {
+				job.input, job.err, job.readErr = bad.Record, bad.Err, nil
+			}
+			select {
+			case jobs <- job:
+			case <-ctx.Done():
+				return
+			}
+			if job.readErr != nil {
+				return
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for w := 0; w < r.Workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobs {
+				if job.readErr == nil && job.err == nil {
+					job.output, job.err = r.mapRecord(ctx, job.input)
+				}
+				results <- job
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	// results arrive in any order and are written in the order read
+	pending := make(map[int]parallelRecord)
+	next := 1
+	var err error
+	for done := range results {
+		if err != nil {
+			continue // drain the remaining results
+		}
+		pending[done.seq] = done
+		for err == nil {
+			record, ok := pending[next]
+			if !ok {
+				break
+			}
+			delete(pending, next)
+			next++
+			if err = parent.Err(); err != nil {
+				break
+			}
+			if record.readErr != nil {
+				err = &RecordError{Record: record.seq, Offset: record.offset, Err: record.readErr}
+				break
+			}
+			result.Read++
+			err = r.handle(&result, record.input, record.offset, record.output, record.err)
+			<-sem
+		}
+		if err != nil {
+			cancel()
+		}
+	}
+	if err != nil {
+		return result, err
+	}
+	if err = parent.Err(); err != nil {
+		return result, err
+	}
+	return result, r.flush()
+}
@@ -0,0 +1,142 @@
+package mapjitsu
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sync"
+)
+
+// ContainerTarget is an optional interface implemented by Targets to
+// identify the container they write to (e.g. an mxj.Map or CSV record).
+// Container must return a comparable key which is the same for all
+// Targets writing to the same container.
+//
+// ApplyConcurrent serialises writes to each container so Targets which
+// are not safe for concurrent use can still be applied concurrently.
+// Writes to Targets which do not implement ContainerTarget are all
+// serialised together.
+type ContainerTarget interface {
+	Container() interface{}
+}
+
+// ApplyConcurrent applies the Mappings using up to workers goroutines,
+// evaluating Sources and Pipelines in parallel. If workers is less than 1
+// runtime.NumCPU() is used.
+//
+// Mappings reading a Var are applied once all the Mappings writing it
+// have completed. Otherwise Mappings must be independent of each other,
+// Sources must be safe for concurrent use and must not read from a
+// container being written to by another Mapping. Errors are reported in
+// Mapping order.
+//
+// Transactional Definitions can not be applied concurrently.
+func (d Definition) ApplyConcurrent(ctx context.Context, workers int) error {
+	if d.Transactional {
+		return fmt.Errorf("transactional definitions can not be applied concurrently")
+	}
+	if workers < 1 {
+		workers = runtime.NumCPU()
+	}
+	levels, err := d.levels()
+	if err != nil {
+		return err
+	}
+	ctx = withVars(ctx)
+	ctx = withRunHooks(ctx, d.Hooks)
+
+	var (
+		wg     sync.WaitGroup
+		locks  containerLocks
+		errs   = make([]error, len(d.Mappings))
+		failed = make(chan struct{})
+		once   sync.Once
+		sem    = make(chan struct{}, workers)
+	)
+
+	fail := func() { once.Do(func() { close(failed) }) }
+
+launch:
+	for _, level := range levels {
+		for _, i := range level {
+			select {
+			case <-failed:
+				break launch
+			default:
+			}
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				errs[i] = ctx.Err()
+				break launch
+			case <-failed:
+				break launch
+			}
+			wg.Add(1)
+			go func(i int, m Mapping) {
+				defer func() {
+					<-sem
+					wg.Done()
+				}()
+				err := m.applyLocked(ctx, i, &locks)
+				if err != nil {
+					errs[i] = err
+					if !d.collects(err) && !isValidationError(err) {
+						fail()
+					}
+				}
+			}(i, d.Mappings[i])
+		}
+		wg.Wait() // variables written in this level are read in the next
+	}
+	wg.Wait()
+
+	return d.result(errs)
+}
+
+func (m Mapping) applyLocked(ctx context.Context, index int, locks *containerLocks) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	v, ok, err := m.evaluate(ctx, index)
+	if err != nil {
+		v, ok, err = m.recover(v, ok, err)
+	}
+	if err != nil || !ok {
+		return err
+	}
+	var key interface{} = sharedContainer{}
+	if ct, ok := m.Target.(ContainerTarget); ok {
+		key = ct.Container()
+	}
+	mu := locks.get(key)
+	mu.Lock()
+	defer mu.Unlock()
+	err = m.set(ctx, index, v)
+	if err != nil && m.recovers(err) {
+		return nil
+	}
+	return err
+}
+
+// sharedContainer is the key used for Targets which do not implement ContainerTarget
+type sharedContainer struct{}
+
+type containerLocks struct {
+	mu    sync.Mutex
+	locks map[interface{}]*sync.Mutex
+}
+
+func (c *containerLocks) get(key interface{}) *sync.Mutex {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.locks == nil {
+		c.locks = make(map[interface{}]*sync.Mutex)
+	}
+	mu, ok := c.locks[key]
+	if !ok {
+		mu = new(sync.Mutex)
+		c.locks[key] = mu
+	}
+	return mu
+}
@@ -0,0 +1,123 @@
+package mapjitsu
+
+import (
+	"context"
+	"errors"
+	"reflect"
+)
+
+// Skip can be returned by a Source or Pipeline function to skip the
+// Mapping without error, leaving its Target unchanged.
+var Skip = errors.New("skip mapping")
+
+// Predicate reports whether a condition holds for a value.
+type Predicate func(v interface{}) (bool, error)
+
+// Equals returns a Predicate which is true for values equal to value.
+func Equals(value interface{}) Predicate {
+	return func(v interface{}) (bool, error) {
+		return reflect.DeepEqual(v, value), nil
+	}
+}
+
+// In returns a Predicate which is true for values equal to any of values.
+func In(values ...interface{}) Predicate {
+	return func(v interface{}) (bool, error) {
+		for _, value := range values {
+			if reflect.DeepEqual(v, value) {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+}
+
+// Not returns a Predicate which is true when p is false.
+func Not(p Predicate) Predicate {
+	return func(v interface{}) (bool, error) {
+		ok, err := p(v)
+		return !ok && err == nil, err
+	}
+}
+
+// Case is a branch of a Switch. When the Case is chosen its Source,
+// or if nil the value switched on, is passed through its Transform.
+type Case struct {
+	When      Predicate
+	Source    Source
+	Transform Pipeline
+}
+
+// Switch is a Source which chooses the first of its Cases matching the
+// value of On, falling back to Default if set. If no Case is chosen the
+// Mapping is skipped.
+type Switch struct {
+	On      Source
+	Cases   []Case
+	Default *Case
+}
+
+func (s Switch) Value() (interface{}, error) {
+	return s.ValueContext(context.Background())
+}
+
+func (s Switch) ValueContext(ctx context.Context) (interface{}, error) {
+	on, err := value(ctx, s.On)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range s.Cases {
+		ok, err := c.When(on)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			return c.value(ctx, on)
+		}
+	}
+	if s.Default != nil {
+		return s.Default.value(ctx, on)
+	}
+	return nil, Skip
+}
+
+func (s Switch) String() string {
+	return "switch on " + describe(s.On)
+}
+
+// ReadsVars returns the variables read by On and the Case Sources.
+func (s Switch) ReadsVars() []string {
+	var vars []string
+	sources := []Source{s.On}
+	for _, c := range s.Cases {
+		sources = append(sources, c.Source)
+	}
+	if s.Default != nil {
+		sources = append(sources, s.Default.Source)
+	}
+	for _, source := range sources {
+		if r, ok := source.(VarReader); ok {
+			vars = append(vars, r.ReadsVars()...)
+		}
+	}
+	return vars
+}
+
+func (c Case) value(ctx context.Context, on interface{}) (interface{}, error) {
+	v := on
+	if c.Source != nil {
+		var err error
+		v, err = value(ctx, c.Source)
+		if err != nil {
+			return nil, err
+		}
+	}
+	for _, f := range c.Transform {
+		var err error
+		v, err = f(v)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return v, nil
+}
@@ -1,7 +1,10 @@
 package data
 
 import (
+	"context"
 	"fmt"
+
+	"github.com/8legd/mapjitsu"
 )
 
 type Source struct {
@@ -23,15 +26,24 @@ func (s Source) Value() (interface{}, error) {
 			}
 		}
 		if s.ColumnNumber < 1 {
-			return nil, fmt.Errorf("ColumnName %s does not exist in Header", s.ColumnName)
+			return nil, mapjitsu.NotFound(fmt.Errorf("ColumnName %s does not exist in Header", s.ColumnName))
 		}
 	}
 	if int(s.ColumnNumber) > len(s.Record) {
-		return nil, fmt.Errorf("invalid column %d, record only contains %d columns", s.ColumnNumber, len(s.Record))
+		return nil, mapjitsu.NotFound(fmt.Errorf("invalid column %d, record only contains %d columns", s.ColumnNumber, len(s.Record)))
 	}
 	return s.Record[s.ColumnNumber-1], nil
 }
 
+func (s Source) String() string {
+	return columnString(s.ColumnNumber, s.ColumnName)
+}
+
+// AsTarget returns the Target for the same column, see mapjitsu.Definition.Reverse.
+func (s Source) AsTarget() mapjitsu.Target {
+	return Target{Header: s.Header, Record: s.Record, ColumnNumber: s.ColumnNumber, ColumnName: s.ColumnName}
+}
+
 type Target struct {
 	Header       []string
 	Record       []string
@@ -64,3 +76,111 @@ func (t Target) SetValue(v interface{}) error {
 	t.Record[t.ColumnNumber-1] = s
 	return nil
 }
+
+func (t Target) String() string {
+	return columnString(t.ColumnNumber, t.ColumnName)
+}
+
+// AsSource returns the Source for the same column, see mapjitsu.Definition.Reverse.
+func (t Target) AsSource() mapjitsu.Source {
+	return Source{Header: t.Header, Record: t.Record, ColumnNumber: t.ColumnNumber, ColumnName: t.ColumnName}
+}
+
+// Container identifies the Record written to, allowing concurrent writes
+// to be serialised per Record.
+func (t Target) Container() interface{} {
+	if len(t.Record) == 0 {
+		return nil
+	}
+	return &t.Record[0]
+}
+
+func columnString(number uint, name string) string {
+	if number < 1 && name != "" {
+		return "csv column " + name
+	}
+	return fmt.Sprintf("csv column %d", number)
+}
+
+// Column refers to a column of the records bound to a mapjitsu.Template,
+// reading from the input record when used as a Source and writing to the
+// output record when used as a Target. Records must be a []string.
+//
+// As with Source and Target either a Number (from 1) or a Header and Name
+// must be given.
+type Column struct {
+	Header []string
+	Number uint
+	Name   string
+}
+
+// Check returns an error if the column can not be resolved.
+func (c Column) Check() error {
+	_, err := c.index()
+	return err
+}
+
+// Value returns an error as a Column can only be read through a Template.
+func (c Column) Value() (interface{}, error) {
+	return nil, fmt.Errorf("%s can only be read through a Template", c)
+}
+
+func (c Column) ValueContext(ctx context.Context) (interface{}, error) {
+	record, ok := mapjitsu.Input(ctx).([]string)
+	if !ok {
+		return nil, fmt.Errorf("input record has invalid type %T, expected []string", mapjitsu.Input(ctx))
+	}
+	i, err := c.index()
+	if err != nil {
+		return nil, err
+	}
+	if i >= len(record) {
+		return nil, mapjitsu.NotFound(fmt.Errorf("invalid column %d, record only contains %d columns", i+1, len(record)))
+	}
+	return record[i], nil
+}
+
+// SetValue returns an error as a Column can only be written through a Template.
+func (c Column) SetValue(v interface{}) error {
+	return fmt.Errorf("%s can only be written through a Template", c)
+}
+
+func (c Column) SetValueContext(ctx context.Context, v interface{}) error {
+	record, ok := mapjitsu.Output(ctx).([]string)
+	if !ok {
+		return fmt.Errorf("output record has invalid type %T, expected []string", mapjitsu.Output(ctx))
+	}
+	i, err := c.index()
+	if err != nil {
+		return err
+	}
+	if i >= len(record) {
+		return fmt.Errorf("invalid column %d, record only contains %d columns", i+1, len(record))
+	}
+	s, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("value has invalid type %T, expected string", v)
+	}
+	record[i] = s
+	return nil
+}
+
+func (c Column) String() string {
+	return columnString(c.Number, c.Name)
+}
+
+// index returns the index of the column in a record
+func (c Column) index() (int, error) {
+	if c.Number > 0 {
+		return int(c.Number - 1), nil
+	}
+	if len(c.Header) < 1 || c.Name == "" {
+		return 0, fmt.Errorf("either a Number from 1 or a Header and Name must be provided")
+	}
+	for index, value := range c.Header {
+		if value == c.Name {
+			return index, nil
+		}
+	}
+	return 0, mapjitsu.NotFound(fmt.Errorf("Name %s does not exist in Header", c.Name))
+}
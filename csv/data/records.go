@@ -0,0 +1,63 @@
+package data
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+
+	"github.com/8legd/mapjitsu"
+)
+
+// Reader is a mapjitsu.RecordReader reading CSV records as []string.
+type Reader struct {
+	CSV *csv.Reader
+}
+
+func NewReader(r io.Reader) *Reader {
+	return &Reader{CSV: csv.NewReader(r)}
+}
+
+// ReadHeader reads the next record as a header.
+func (r *Reader) ReadHeader() ([]string, error) {
+	header, err := r.CSV.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read header %v", err)
+	}
+	return header, nil
+}
+
+// Read reads the next record. A record with the wrong number of fields
+// is returned as a *mapjitsu.BadRecordError, so a mapjitsu.Runner can
+// skip or quarantine it and continue reading.
+func (r *Reader) Read() (interface{}, error) {
+	record, err := r.CSV.Read()
+	if err, ok := err.(*csv.ParseError); ok && err.Err == csv.ErrFieldCount {
+		return nil, &mapjitsu.BadRecordError{Record: record, Err: err}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+// Writer is a mapjitsu.RecordWriter writing []string records as CSV.
+type Writer struct {
+	CSV *csv.Writer
+}
+
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{CSV: csv.NewWriter(w)}
+}
+
+func (w *Writer) Write(record interface{}) error {
+	r, ok := record.([]string)
+	if !ok {
+		return fmt.Errorf("record has invalid type %T, expected []string", record)
+	}
+	return w.CSV.Write(r)
+}
+
+func (w *Writer) Flush() error {
+	w.CSV.Flush()
+	return w.CSV.Error()
+}
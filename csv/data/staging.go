@@ -0,0 +1,64 @@
+package data
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/8legd/mapjitsu"
+)
+
+// Stage checks the column and value, staging a write to be made by
+// Commit, for use by transactional Definitions.
+func (t Target) Stage(ctx context.Context, v interface{}) (mapjitsu.Staged, error) {
+	c := Column{Header: t.Header, Number: t.ColumnNumber, Name: t.ColumnName}
+	return stage(t.Record, c, v)
+}
+
+// Stage checks the column and value, staging a write to the output
+// record, for use by transactional Definitions.
+func (c Column) Stage(ctx context.Context, v interface{}) (mapjitsu.Staged, error) {
+	record, ok := mapjitsu.Output(ctx).([]string)
+	if !ok {
+		return nil, fmt.Errorf("output record has invalid type %T, expected []string", mapjitsu.Output(ctx))
+	}
+	return stage(record, c, v)
+}
+
+func stage(record []string, c Column, v interface{}) (mapjitsu.Staged, error) {
+	i, err := c.index()
+	if err != nil {
+		return nil, err
+	}
+	if i >= len(record) {
+		return nil, fmt.Errorf("invalid column %d, record only contains %d columns", i+1, len(record))
+	}
+	s, ok := v.(string)
+	if !ok {
+		return nil, fmt.Errorf("value has invalid type %T, expected string", v)
+	}
+	return &staged{record: record, index: i, value: s}, nil
+}
+
+// staged is a write to a column which can be rolled back to the previous value
+type staged struct {
+	record    []string
+	index     int
+	value     string
+	previous  string
+	committed bool
+}
+
+func (s *staged) Commit() error {
+	s.previous = s.record[s.index]
+	s.record[s.index] = s.value
+	s.committed = true
+	return nil
+}
+
+func (s *staged) Rollback() error {
+	if s.committed {
+		s.record[s.index] = s.previous
+		s.committed = false
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+package mapjitsu
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrNotFound is matched, using errors.Is, by the errors of Sources
+// reporting that a value is absent, as opposed to present but null.
+var ErrNotFound = errors.New("not found")
+
+// NotFound wraps err so it matches ErrNotFound, for Sources to report
+// that a value is absent.
+func NotFound(err error) error {
+	return &notFoundError{err: err}
+}
+
+type notFoundError struct {
+	err error
+}
+
+func (e *notFoundError) Error() string {
+	return e.err.Error()
+}
+
+func (e *notFoundError) Is(target error) bool {
+	return target == ErrNotFound
+}
+
+func (e *notFoundError) Unwrap() error {
+	return e.err
+}
+
+// Stage identifies the part of a Mapping that was executing.
+type Stage int
+
+const (
+	StageSource Stage = iota
+	StageTransform
+	StageTarget
+	StageCondition
+	StageValidation
+)
+
+func (s Stage) String() string {
+	switch s {
+	case StageSource:
+		return "source"
+	case StageTransform:
+		return "transform"
+	case StageTarget:
+		return "target"
+	case StageCondition:
+		return "condition"
+	case StageValidation:
+		return "validation"
+	}
+	return "stage " + strconv.Itoa(int(s))
+}
+
+// MappingError records a failure applying a Mapping, identifying the
+// Mapping by its index in the Definition and optional Name along with
+// the Stage (and for transforms the Pipeline Step) that failed.
+type MappingError struct {
+	Index int
+	Name  string
+	Stage Stage
+	Step  int         // index into the Pipeline when Stage is StageTransform
+	Value interface{} // the value at the time of failure
+	Err   error
+}
+
+func (e *MappingError) Error() string {
+	var b strings.Builder
+	b.WriteString("mapping ")
+	b.WriteString(strconv.Itoa(e.Index))
+	if e.Name != "" {
+		b.WriteString(" (")
+		b.WriteString(e.Name)
+		b.WriteString(")")
+	}
+	b.WriteString(" failed at ")
+	b.WriteString(e.Stage.String())
+	if e.Stage == StageTransform {
+		b.WriteString(" ")
+		b.WriteString(strconv.Itoa(e.Step))
+	}
+	if e.Stage != StageSource {
+		fmt.Fprintf(&b, " with value %#v", e.Value)
+	}
+	b.WriteString(": ")
+	b.WriteString(e.Err.Error())
+	return b.String()
+}
+
+func (e *MappingError) Unwrap() error {
+	return e.Err
+}
+
+// Errors is a list of errors collected while applying a Definition
+// with CollectErrors set. It is compatible with errors.Is and errors.As
+// which report a match if any of the collected errors match.
+type Errors []error
+
+func (e Errors) Error() string {
+	switch len(e) {
+	case 0:
+		return "no errors"
+	case 1:
+		return e[0].Error()
+	}
+	var b strings.Builder
+	b.WriteString(strconv.Itoa(len(e)))
+	b.WriteString(" errors occurred:")
+	for _, err := range e {
+		b.WriteString("\n\t* ")
+		b.WriteString(err.Error())
+	}
+	return b.String()
+}
+
+// Is reports whether any of the collected errors matches target.
+func (e Errors) Is(target error) bool {
+	for _, err := range e {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// As finds the first of the collected errors that matches target.
+func (e Errors) As(target interface{}) bool {
+	for _, err := range e {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,149 @@
+package mapjitsu
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+)
+
+// ForEach is a Source which maps each element of the list read from
+// Source with Template, returning the list of outputs e.g. to map each of
+// the lines of an order to the items of an invoice
+//
+//	{
+//		Source: mapjitsu.ForEach{Source: mxjData.List{Path: "lines"}, Template: item},
+//		Target: mxjData.Path("Items"),
+//	}
+//
+// Each element is bound as the input record of Template, so element
+// relative Sources such as mxj/data Path read from the element, and a new
+// output record from NewOutput is bound as the output. ForEach may be
+// nested, the Template of one ForEach using another as a Source, and the
+// position of each element is available through Index.
+//
+// A nil list is returned as nil.
+type ForEach struct {
+	Source   Source
+	Template *Template
+
+	// NewOutput returns the output record for each element, by default
+	// an empty map[string]interface{}.
+	NewOutput func() interface{}
+}
+
+func (f ForEach) Value() (interface{}, error) {
+	return f.ValueContext(context.Background())
+}
+
+func (f ForEach) ValueContext(ctx context.Context) (interface{}, error) {
+	v, err := value(ctx, f.Source)
+	if err != nil || v == nil {
+		return nil, err
+	}
+	list := reflect.ValueOf(v)
+	if list.Kind() != reflect.Slice && list.Kind() != reflect.Array {
+		return nil, fmt.Errorf("value has invalid type %T, expected a list", v)
+	}
+	indices, _ := ctx.Value(indicesKey{}).([]int)
+	result := make([]interface{}, list.Len())
+	for i := range result {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		var output interface{} = make(map[string]interface{})
+		if f.NewOutput != nil {
+			output = f.NewOutput()
+		}
+		elementIndices := append([]int{i}, indices...)
+		err = f.Template.ExecuteContext(context.WithValue(ctx, indicesKey{}, elementIndices), list.Index(i).Interface(), output)
+		if err != nil {
+			return nil, &ElementError{Index: i, Err: err}
+		}
+		result[i] = output
+	}
+	return result, nil
+}
+
+func (f ForEach) String() string {
+	return "for each of " + describe(f.Source)
+}
+
+// ReadsVars returns the variables read by Source.
+func (f ForEach) ReadsVars() []string {
+	if r, ok := f.Source.(VarReader); ok {
+		return r.ReadsVars()
+	}
+	return nil
+}
+
+// Check checks Source and Template are set, and Source if it is a Checker.
+func (f ForEach) Check() error {
+	if f.Source == nil {
+		return fmt.Errorf("missing for each source")
+	}
+	if f.Template == nil {
+		return fmt.Errorf("missing for each template")
+	}
+	if c, ok := f.Source.(Checker); ok {
+		return c.Check()
+	}
+	return nil
+}
+
+// ElementError reports the failure to map the element at Index of a ForEach.
+type ElementError struct {
+	Index int
+	Err   error
+}
+
+func (e *ElementError) Error() string {
+	return fmt.Sprintf("element %d: %v", e.Index, e.Err)
+}
+
+func (e *ElementError) Unwrap() error {
+	return e.Err
+}
+
+// indicesKey holds the positions of the elements being mapped by ForEach,
+// innermost first
+type indicesKey struct{}
+
+// Index is a Source returning the position (from 0) of the element being
+// mapped by a ForEach, Index(0) for the innermost ForEach, Index(1) for
+// the ForEach enclosing it and so on.
+type Index int
+
+func (i Index) Value() (interface{}, error) {
+	return nil, fmt.Errorf("%s can only be read within a ForEach", i)
+}
+
+func (i Index) ValueContext(ctx context.Context) (interface{}, error) {
+	indices, _ := ctx.Value(indicesKey{}).([]int)
+	if int(i) < 0 || int(i) >= len(indices) {
+		return nil, fmt.Errorf("%s can only be read within %d nested ForEach", i, int(i)+1)
+	}
+	return indices[i], nil
+}
+
+func (i Index) String() string {
+	return fmt.Sprintf("index %d", int(i))
+}
+
+// Element is a Source returning the element being mapped by a ForEach,
+// e.g. for lists of strings rather than objects.
+type Element struct{}
+
+func (Element) Value() (interface{}, error) {
+	return nil, fmt.Errorf("element can only be read within a ForEach")
+}
+
+func (Element) ValueContext(ctx context.Context) (interface{}, error) {
+	if _, ok := ctx.Value(indicesKey{}).([]int); !ok {
+		return nil, fmt.Errorf("element can only be read within a ForEach")
+	}
+	return Input(ctx), nil
+}
+
+func (Element) String() string {
+	return "element"
+}
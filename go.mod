@@ -1,5 +1,8 @@
 module github.com/8legd/mapjitsu
 
-go 1.12
+go 1.14
 
-require github.com/clbanning/mxj v1.8.4
+require (
+	github.com/clbanning/mxj v1.8.4
+	gopkg.in/yaml.v3 v3.0.1
+)
@@ -0,0 +1,135 @@
+package mapjitsu
+
+import (
+	"context"
+	"time"
+)
+
+// HookEvent describes a point in the execution of a Mapping.
+type HookEvent struct {
+	Index    int // index of the Mapping in the Definition
+	Mapping  Mapping
+	Stage    Stage
+	Step     int           // index into the Pipeline for AfterStep
+	Value    interface{}   // the value read, transformed or to be written
+	Duration time.Duration // time taken by the Source, Pipeline step or Target
+}
+
+// Hooks observe the execution of Mappings, e.g. for logging or timing.
+// Hooks used with ApplyConcurrent must be safe for concurrent use.
+//
+// Embed NopHooks to implement only some of the methods.
+type Hooks interface {
+	BeforeSource(e HookEvent)
+	AfterSource(e HookEvent)
+	AfterStep(e HookEvent)
+	BeforeTarget(e HookEvent)
+	AfterTarget(e HookEvent)
+	// OnError is called with the *MappingError, or for validation
+	// failures the *ValidationReport, for a Mapping. It is not called for
+	// errors recovered by the ErrorPolicy of the Mapping.
+	OnError(e HookEvent, err error)
+}
+
+// NopHooks implements Hooks without doing anything.
+type NopHooks struct{}
+
+func (NopHooks) BeforeSource(HookEvent)   {}
+func (NopHooks) AfterSource(HookEvent)    {}
+func (NopHooks) AfterStep(HookEvent)      {}
+func (NopHooks) BeforeTarget(HookEvent)   {}
+func (NopHooks) AfterTarget(HookEvent)    {}
+func (NopHooks) OnError(HookEvent, error) {}
+
+// WithHooks returns a copy of ctx carrying hooks, which are called
+// alongside any Definition Hooks by ApplyContext, ApplyConcurrent and
+// PlanContext.
+func WithHooks(ctx context.Context, hooks Hooks) context.Context {
+	if h, ok := ctx.Value(hooksKey{}).(Hooks); ok {
+		hooks = multiHooks{h, hooks}
+	}
+	return context.WithValue(ctx, hooksKey{}, hooks)
+}
+
+type hooksKey struct{}
+
+// runHooksKey holds the hooks for the current run, combining the
+// Definition Hooks with any from WithHooks
+type runHooksKey struct{}
+
+func withRunHooks(ctx context.Context, hooks Hooks) context.Context {
+	if h, ok := ctx.Value(hooksKey{}).(Hooks); ok {
+		if hooks == nil {
+			hooks = h
+		} else {
+			hooks = multiHooks{hooks, h}
+		}
+	}
+	return context.WithValue(ctx, runHooksKey{}, hooks)
+}
+
+func runHooks(ctx context.Context) Hooks {
+	h, _ := ctx.Value(runHooksKey{}).(Hooks)
+	return h
+}
+
+type multiHooks []Hooks
+
+func (m multiHooks) BeforeSource(e HookEvent) {
+	for _, h := range m {
+		h.BeforeSource(e)
+	}
+}
+
+func (m multiHooks) AfterSource(e HookEvent) {
+	for _, h := range m {
+		h.AfterSource(e)
+	}
+}
+
+func (m multiHooks) AfterStep(e HookEvent) {
+	for _, h := range m {
+		h.AfterStep(e)
+	}
+}
+
+func (m multiHooks) BeforeTarget(e HookEvent) {
+	for _, h := range m {
+		h.BeforeTarget(e)
+	}
+}
+
+func (m multiHooks) AfterTarget(e HookEvent) {
+	for _, h := range m {
+		h.AfterTarget(e)
+	}
+}
+
+func (m multiHooks) OnError(e HookEvent, err error) {
+	for _, h := range m {
+		h.OnError(e, err)
+	}
+}
+
+// event returns the HookEvent for an error returned from evaluating a Mapping
+func (m Mapping) event(index int, v interface{}, err error) HookEvent {
+	e := HookEvent{Index: index, Mapping: m, Value: v}
+	switch err := err.(type) {
+	case *MappingError:
+		e.Stage = err.Stage
+		e.Step = err.Step
+		e.Value = err.Value
+	case *validationError:
+		e.Stage = StageValidation
+		e.Value = err.failures[0].Value
+	}
+	return e
+}
+
+// hookError converts validation failures for a Mapping into a ValidationReport
+func hookError(err error) error {
+	if v, ok := err.(*validationError); ok {
+		return &ValidationReport{Failures: v.failures}
+	}
+	return err
+}
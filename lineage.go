@@ -0,0 +1,118 @@
+package mapjitsu
+
+import (
+	"encoding/json"
+	"reflect"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// LineageRecord describes where the value written by a Mapping came from.
+type LineageRecord struct {
+	Index      int         `json:"index"`
+	Name       string      `json:"name,omitempty"`
+	Source     string      `json:"source"`
+	Transforms []string    `json:"transforms,omitempty"`
+	Input      interface{} `json:"input"`
+	Output     interface{} `json:"output"`
+	Target     string      `json:"target"`
+}
+
+// Lineage is a set of Hooks recording a LineageRecord for each value
+// written, e.g.
+//
+//	lineage := &mapjitsu.Lineage{}
+//	definition.Hooks = lineage
+//
+// Sources and Targets are described by their String method, if they have
+// one, and transforms by their description, see Describe, or otherwise
+// the name of their function. Use a new Lineage for each Apply.
+type Lineage struct {
+	NopHooks
+
+	mu      sync.Mutex
+	pending map[int]interface{}
+	records []LineageRecord
+}
+
+func (l *Lineage) AfterSource(e HookEvent) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.pending == nil {
+		l.pending = make(map[int]interface{})
+	}
+	l.pending[e.Index] = e.Value
+}
+
+func (l *Lineage) AfterTarget(e HookEvent) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	input := l.pending[e.Index]
+	delete(l.pending, e.Index)
+	var transforms []string
+	for _, f := range e.Mapping.Transform {
+		transforms = append(transforms, transformName(f))
+	}
+	l.records = append(l.records, LineageRecord{
+		Index:      e.Index,
+		Name:       e.Mapping.Name,
+		Source:     describe(e.Mapping.Source),
+		Transforms: transforms,
+		Input:      input,
+		Output:     e.Value,
+		Target:     describe(e.Mapping.Target),
+	})
+}
+
+// Records returns the recorded lineage in the order values were written.
+func (l *Lineage) Records() []LineageRecord {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]LineageRecord(nil), l.records...)
+}
+
+// JSON returns the recorded lineage as a JSON array.
+func (l *Lineage) JSON() ([]byte, error) {
+	return json.Marshal(l.Records())
+}
+
+// Describe returns a transform applying f which is recorded in lineage as
+// description, e.g. transforms.Trim("-") rather than the name of f.
+//
+//go:noinline
+func Describe(description string, f func(interface{}) (interface{}, error)) func(interface{}) (interface{}, error) {
+	return func(v interface{}) (interface{}, error) {
+		if _, ok := v.(describeRequest); ok {
+			return description, nil
+		}
+		return f(v)
+	}
+}
+
+// describeRequest is passed to a transform returned by Describe to
+// return its description
+type describeRequest struct{}
+
+// described identifies the transforms returned by Describe, which all
+// share the code of the same function literal as Describe is not inlined
+var described = reflect.ValueOf(Describe("", nil)).Pointer()
+
+// transformName returns the description of f, or the name of its function
+// e.g. tests.TestLineage.func1 for a function literal
+func transformName(f func(interface{}) (interface{}, error)) string {
+	pc := reflect.ValueOf(f).Pointer()
+	if pc == described {
+		description, _ := f(describeRequest{})
+		return description.(string)
+	}
+	fn := runtime.FuncForPC(pc)
+	if fn == nil {
+		return "unknown"
+	}
+	name := fn.Name()
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:]
+	}
+	return name
+}
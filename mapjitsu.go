@@ -1,9 +1,41 @@
 package mapjitsu
 
+import (
+	"context"
+	"time"
+)
+
 type Mapping struct {
+	Name      string // optional name or description used in errors
 	Source    Source
 	Transform Pipeline
 	Target    Target
+
+	// Inverse optionally declares the inverse of each step of the
+	// Transform, used by Definition.Reverse. A missing or nil step is not
+	// invertible.
+	Inverse Pipeline
+
+	// When optionally makes the Mapping conditional, it is skipped unless
+	// When returns true for the value of the Source (before any Transform)
+	// or, if WhenSource is set, for the value of WhenSource.
+	When       Predicate
+	WhenSource Source
+
+	// OnError determines how a failure of the Mapping is handled, see
+	// ErrorPolicy. Default is the value written with DefaultOnError.
+	OnError ErrorPolicy
+	Default interface{}
+
+	// Optional skips the Mapping, rather than failing, when the Source
+	// (or WhenSource) reports that its value is absent, see ErrNotFound.
+	// Use the Optional Source to write a default value instead.
+	Optional bool
+
+	// Validate optionally checks the value after the Transform Pipeline.
+	// If any Validator fails the Target is not set and the failures are
+	// reported in a ValidationReport once every Mapping has been applied.
+	Validate []Validator
 }
 
 type Source interface {
@@ -21,6 +53,27 @@ func (f SourceFunc) Value() (interface{}, error) {
 	return f()
 }
 
+// ContextSource is an optional interface implemented by Sources which
+// accept a context. When implemented ApplyContext calls ValueContext
+// in preference to Value.
+type ContextSource interface {
+	ValueContext(ctx context.Context) (interface{}, error)
+}
+
+// The ContextSourceFunc type is an adapter to allow the use of
+// ordinary functions accepting a context as Sources.
+type ContextSourceFunc func(ctx context.Context) (interface{}, error)
+
+// Value returns f(context.Background()).
+func (f ContextSourceFunc) Value() (interface{}, error) {
+	return f(context.Background())
+}
+
+// ValueContext returns f(ctx).
+func (f ContextSourceFunc) ValueContext(ctx context.Context) (interface{}, error) {
+	return f(ctx)
+}
+
 type Pipeline []func(interface{}) (interface{}, error)
 
 type Target interface {
@@ -38,30 +91,233 @@ func (f TargetFunc) SetValue(v interface{}) error {
 	return f(v)
 }
 
+// ContextTarget is an optional interface implemented by Targets which
+// accept a context. When implemented ApplyContext calls SetValueContext
+// in preference to SetValue.
+type ContextTarget interface {
+	SetValueContext(ctx context.Context, v interface{}) error
+}
+
+// The ContextTargetFunc type is an adapter to allow the use of
+// ordinary functions accepting a context as Targets.
+type ContextTargetFunc func(ctx context.Context, v interface{}) error
+
+// SetValue calls f(context.Background(), v).
+func (f ContextTargetFunc) SetValue(v interface{}) error {
+	return f(context.Background(), v)
+}
+
+// SetValueContext calls f(ctx, v).
+func (f ContextTargetFunc) SetValueContext(ctx context.Context, v interface{}) error {
+	return f(ctx, v)
+}
+
 type Definition struct {
 	Mappings []Mapping
+
+	// CollectErrors makes Apply run every Mapping rather than stopping at
+	// the first failure, returning any failures together as Errors.
+	CollectErrors bool
+
+	// Hooks are optionally called as each Mapping is executed.
+	Hooks Hooks
+
+	// Transactional makes Apply all or nothing, Target writes are staged
+	// and only made once every Mapping has succeeded. If a write then fails
+	// the writes already made to any StagingTarget are rolled back.
+	Transactional bool
 }
 
 func (d Definition) Apply() error {
-	for _, m := range d.Mappings {
+	return d.ApplyContext(context.Background())
+}
+
+// ApplyContext applies the Mappings in order, passing ctx to any
+// ContextSource or ContextTarget. Once ctx is done no further Mappings
+// are applied and the context error is returned.
+//
+// Mappings reading a Var are applied after the Mappings writing it.
+func (d Definition) ApplyContext(ctx context.Context) error {
+	order, err := d.order()
+	if err != nil {
+		return err
+	}
+	return d.apply(ctx, order)
+}
+
+func (d Definition) apply(ctx context.Context, order []int) error {
+	if d.Transactional {
+		return d.applyTransaction(ctx, order)
+	}
+	return d.runOrder(ctx, order, Mapping.set)
+}
+
+// run evaluates the Mappings in order, passing each value to set
+func (d Definition) run(ctx context.Context, set func(m Mapping, ctx context.Context, index int, v interface{}) error) error {
+	order, err := d.order()
+	if err != nil {
+		return err
+	}
+	return d.runOrder(ctx, order, set)
+}
+
+func (d Definition) runOrder(ctx context.Context, order []int, set func(m Mapping, ctx context.Context, index int, v interface{}) error) error {
+	ctx = withVars(ctx)
+	ctx = withRunHooks(ctx, d.Hooks)
 
-		v, err := m.Source.Value()
+	var errs []error
+	for _, i := range order {
+		m := d.Mappings[i]
+		if err := ctx.Err(); err != nil {
+			errs = append(errs, err)
+			break
+		}
+		v, ok, err := m.evaluate(ctx, i)
 		if err != nil {
-			return err
+			v, ok, err = m.recover(v, ok, err)
 		}
-
-		for _, f := range m.Transform {
-			v, err = f(v)
-			if err != nil {
-				return err
+		if err == nil && ok {
+			err = set(m, ctx, i, v)
+			if err != nil && m.recovers(err) {
+				err = nil
 			}
 		}
+		if err != nil {
+			errs = append(errs, err)
+			if !d.collects(err) && !isValidationError(err) {
+				break
+			}
+		}
+	}
+	return d.result(errs)
+}
+
+// evaluate returns the value of the Source after the Transform Pipeline,
+// or false if the Mapping should be skipped
+func (m Mapping) evaluate(ctx context.Context, index int) (interface{}, bool, error) {
+	hooks := runHooks(ctx)
+	v, ok, err := m.evaluateHooked(ctx, index, hooks)
+	if err != nil && hooks != nil && !m.recovers(err) {
+		hooks.OnError(m.event(index, v, err), hookError(err))
+	}
+	return v, ok, err
+}
+
+func (m Mapping) evaluateHooked(ctx context.Context, index int, hooks Hooks) (interface{}, bool, error) {
+	if m.When != nil && m.WhenSource != nil {
+		ok, err := m.condition(ctx, index, m.WhenSource)
+		if err != nil || !ok {
+			return nil, false, err
+		}
+	}
+
+	var start time.Time
+	if hooks != nil {
+		hooks.BeforeSource(HookEvent{Index: index, Mapping: m, Stage: StageSource})
+		start = time.Now()
+	}
+	v, err := value(ctx, m.Source)
+	if err == Skip || m.absent(err) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, m.error(index, StageSource, 0, v, err)
+	}
+	if hooks != nil {
+		hooks.AfterSource(HookEvent{Index: index, Mapping: m, Stage: StageSource, Value: v, Duration: time.Since(start)})
+	}
 
-		err = m.Target.SetValue(v)
+	if m.When != nil && m.WhenSource == nil {
+		ok, err := m.test(index, v)
+		if err != nil || !ok {
+			return nil, false, err
+		}
+	}
+
+	for step, f := range m.Transform {
+		if hooks != nil {
+			start = time.Now()
+		}
+		var result interface{}
+		result, err = f(v)
+		if err == Skip {
+			return nil, false, nil
+		}
 		if err != nil {
-			return err
+			return nil, false, m.error(index, StageTransform, step, v, err)
 		}
+		v = result
+		if hooks != nil {
+			hooks.AfterStep(HookEvent{Index: index, Mapping: m, Stage: StageTransform, Step: step, Value: v, Duration: time.Since(start)})
+		}
+	}
+
+	err = m.validate(index, v)
+	if err != nil {
+		return nil, false, err
+	}
+	return v, true, nil
+}
 
+func (m Mapping) condition(ctx context.Context, index int, s Source) (bool, error) {
+	v, err := value(ctx, s)
+	if err == Skip || m.absent(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, m.error(index, StageCondition, 0, v, err)
+	}
+	return m.test(index, v)
+}
+
+// absent reports whether err means the value of an Optional Mapping is absent
+func (m Mapping) absent(err error) bool {
+	return m.Optional && notFound(err)
+}
+
+func (m Mapping) test(index int, v interface{}) (bool, error) {
+	ok, err := m.When(v)
+	if err != nil {
+		return false, m.error(index, StageCondition, 0, v, err)
+	}
+	return ok, nil
+}
+
+func (m Mapping) set(ctx context.Context, index int, v interface{}) error {
+	hooks := runHooks(ctx)
+	var start time.Time
+	if hooks != nil {
+		hooks.BeforeTarget(HookEvent{Index: index, Mapping: m, Stage: StageTarget, Value: v})
+		start = time.Now()
+	}
+	err := setValue(ctx, m.Target, v)
+	if err != nil {
+		mappingError := m.error(index, StageTarget, 0, v, err)
+		if hooks != nil && !m.recovers(mappingError) {
+			hooks.OnError(m.event(index, v, mappingError), mappingError)
+		}
+		return mappingError
+	}
+	if hooks != nil {
+		hooks.AfterTarget(HookEvent{Index: index, Mapping: m, Stage: StageTarget, Value: v, Duration: time.Since(start)})
 	}
 	return nil
 }
+
+func (m Mapping) error(index int, stage Stage, step int, v interface{}, err error) *MappingError {
+	return &MappingError{Index: index, Name: m.Name, Stage: stage, Step: step, Value: v, Err: err}
+}
+
+func value(ctx context.Context, s Source) (interface{}, error) {
+	if cs, ok := s.(ContextSource); ok {
+		return cs.ValueContext(ctx)
+	}
+	return s.Value()
+}
+
+func setValue(ctx context.Context, t Target, v interface{}) error {
+	if ct, ok := t.(ContextTarget); ok {
+		return ct.SetValueContext(ctx, v)
+	}
+	return t.SetValue(v)
+}
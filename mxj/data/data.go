@@ -1,11 +1,19 @@
 package data
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"reflect"
+	"strings"
 
+	"github.com/8legd/mapjitsu"
 	"github.com/clbanning/mxj"
 )
 
+// Source reads the value at Path of Map, see mxj.Map.ValueForPath. As for
+// ValueForPath a list at the end of the path returns its first element,
+// use List to read the whole list.
 type Source struct {
 	Map     mxj.Map
 	Path    string
@@ -18,24 +26,205 @@ func (s Source) Value() (interface{}, error) {
 		if s.OnError != nil { // optional error handler
 			return s.OnError(s.Path, v, err)
 		}
-		return nil, fmt.Errorf("failed to return %s %v", s.Path, err)
+		return nil, notFound(fmt.Errorf("failed to return %s %w", s.Path, err))
 	}
 	return v, nil
 }
 
+func (s Source) String() string {
+	return "mxj path " + s.Path
+}
+
+// AsTarget returns the Target for the same path, see mapjitsu.Definition.Reverse.
+func (s Source) AsTarget() mapjitsu.Target {
+	return Target{Map: s.Map, Path: s.Path}
+}
+
+// Target writes values at Path of Map, see mxj.Map.SetValueForPath.
 type Target struct {
 	Map     mxj.Map
 	Path    string
 	OnError func(path string, v interface{}, err error) error
+
+	// Create optionally creates any missing maps and list elements along
+	// Path, which may index lists e.g. Items[2].Name, rather than
+	// requiring them to exist.
+	Create bool
+
+	// Strategy determines how a value already at Path is treated, by
+	// default it is overwritten.
+	Strategy Strategy
 }
 
 func (t Target) SetValue(v interface{}) error {
-	err := t.Map.SetValueForPath(v, t.Path)
+	err := t.set(v)
 	if err != nil {
 		if t.OnError != nil { // optional error handler
 			return t.OnError(t.Path, v, err)
 		}
-		return fmt.Errorf("failed to set %s %v", t.Path, err)
+		return fmt.Errorf("failed to set %s %w", t.Path, err)
 	}
 	return nil
 }
+
+func (t Target) String() string {
+	return "mxj path " + t.Path
+}
+
+func (t Target) set(v interface{}) error {
+	if t.Strategy != Overwrite {
+		existing, err := valueForPath(t.Map, t.Path)
+		var ok bool
+		v, ok, err = t.Strategy.apply(existing, err == nil, v)
+		if err != nil || !ok {
+			return err
+		}
+	}
+	if t.Create {
+		return setValueForPath(t.Map, t.Path, v)
+	}
+	return t.Map.SetValueForPath(v, t.Path)
+}
+
+// AsSource returns the Source for the same path, see mapjitsu.Definition.Reverse.
+func (t Target) AsSource() mapjitsu.Source {
+	return Source{Map: t.Map, Path: t.Path}
+}
+
+// Container identifies the Map written to, allowing concurrent writes
+// to be serialised per Map.
+func (t Target) Container() interface{} {
+	return reflect.ValueOf(t.Map).Pointer()
+}
+
+// Path refers to a path within the records bound to a mapjitsu.Template,
+// reading from the input record when used as a Source and writing to the
+// output record when used as a Target. Records must be an mxj.Map or a
+// map[string]interface{}. Writes create any missing maps and list
+// elements along the path, as for a Target with Create, and overwrite any
+// existing value.
+type Path string
+
+// Value returns an error as a Path can only be read through a Template.
+func (p Path) Value() (interface{}, error) {
+	return nil, fmt.Errorf("%s can only be read through a Template", p)
+}
+
+func (p Path) ValueContext(ctx context.Context) (interface{}, error) {
+	m, ok := toMap(mapjitsu.Input(ctx))
+	if !ok {
+		return nil, fmt.Errorf("input record has invalid type %T, expected mxj.Map", mapjitsu.Input(ctx))
+	}
+	v, err := m.ValueForPath(string(p))
+	if err != nil {
+		return nil, notFound(fmt.Errorf("failed to return %s %w", string(p), err))
+	}
+	return v, nil
+}
+
+// SetValue returns an error as a Path can only be written through a Template.
+func (p Path) SetValue(v interface{}) error {
+	return fmt.Errorf("%s can only be written through a Template", p)
+}
+
+func (p Path) SetValueContext(ctx context.Context, v interface{}) error {
+	m, ok := toMap(mapjitsu.Output(ctx))
+	if !ok {
+		return fmt.Errorf("output record has invalid type %T, expected mxj.Map", mapjitsu.Output(ctx))
+	}
+	err := setValueForPath(m, string(p), v)
+	if err != nil {
+		return fmt.Errorf("failed to set %s %w", string(p), err)
+	}
+	return nil
+}
+
+func (p Path) String() string {
+	return "mxj path " + string(p)
+}
+
+// List is a Source reading the whole list at Path of Map, e.g. for
+// mapjitsu.ForEach, where a Source or Path would read its first element.
+// A value which is not a list is returned as a list of one value.
+//
+// If Map is nil the input record bound to a mapjitsu.Template is read.
+type List struct {
+	Map  mxj.Map
+	Path string
+}
+
+func (l List) Value() (interface{}, error) {
+	if l.Map == nil {
+		return nil, fmt.Errorf("%s without a Map can only be read through a Template", l)
+	}
+	return l.list(l.Map)
+}
+
+func (l List) ValueContext(ctx context.Context) (interface{}, error) {
+	if l.Map != nil {
+		return l.list(l.Map)
+	}
+	m, ok := toMap(mapjitsu.Input(ctx))
+	if !ok {
+		return nil, fmt.Errorf("input record has invalid type %T, expected mxj.Map", mapjitsu.Input(ctx))
+	}
+	return l.list(m)
+}
+
+func (l List) String() string {
+	return "mxj list " + l.Path
+}
+
+func (l List) list(m mxj.Map) (interface{}, error) {
+	v, err := valueForPath(m, l.Path)
+	if err != nil {
+		return nil, notFound(fmt.Errorf("failed to return %s %w", l.Path, err))
+	}
+	if list, ok := v.([]interface{}); ok {
+		return list, nil
+	}
+	return []interface{}{v}, nil
+}
+
+// valueForPath returns the value at path as for mxj.Map.ValueForPath,
+// except a list at the end of the path is returned whole rather than as
+// its first element
+func valueForPath(m mxj.Map, path string) (interface{}, error) {
+	v, err := m.ValueForPath(path)
+	if err != nil && err != mxj.PathNotExistError {
+		return nil, err
+	}
+	var parent interface{} = map[string]interface{}(m)
+	key := path
+	if i := strings.LastIndex(path, "."); i >= 0 {
+		p, parentErr := m.ValueForPath(path[:i])
+		if parentErr != nil {
+			return v, err
+		}
+		parent, key = p, path[i+1:]
+	}
+	if pm, ok := parent.(map[string]interface{}); ok {
+		if list, ok := pm[key].([]interface{}); ok {
+			return list, nil
+		}
+	}
+	return v, err
+}
+
+// notFound marks a missing path so it matches mapjitsu.ErrNotFound.
+func notFound(err error) error {
+	if errors.Is(err, mxj.PathNotExistError) {
+		return mapjitsu.NotFound(err)
+	}
+	return err
+}
+
+func toMap(record interface{}) (mxj.Map, bool) {
+	switch m := record.(type) {
+	case mxj.Map:
+		return m, true
+	case map[string]interface{}:
+		return mxj.Map(m), true
+	}
+	return nil, false
+}
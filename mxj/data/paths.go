@@ -0,0 +1,96 @@
+package data
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/clbanning/mxj"
+)
+
+// step is a key of a map or, if index is not negative, an element of a
+// list along a path, with the path up to and including it
+type step struct {
+	key   string
+	index int
+	path  string
+}
+
+var segmentPattern = regexp.MustCompile(`^([^\[\]]+)((?:\[\d+\])*)$`)
+var indexPattern = regexp.MustCompile(`\[(\d+)\]`)
+
+// steps splits a path such as Items[2].Name into its steps
+func steps(path string) ([]step, error) {
+	var result []step
+	var prefix string
+	for _, segment := range strings.Split(path, ".") {
+		match := segmentPattern.FindStringSubmatch(segment)
+		if match == nil {
+			return nil, fmt.Errorf("invalid path segment %q", segment)
+		}
+		if prefix != "" {
+			prefix += "."
+		}
+		prefix += match[1]
+		result = append(result, step{key: match[1], index: -1, path: prefix})
+		for _, index := range indexPattern.FindAllStringSubmatch(match[2], -1) {
+			i, err := strconv.Atoi(index[1])
+			if err != nil {
+				return nil, fmt.Errorf("invalid path segment %q", segment)
+			}
+			prefix += index[0]
+			result = append(result, step{index: i, path: prefix})
+		}
+	}
+	return result, nil
+}
+
+// setValueForPath sets v at path, creating any missing maps and list
+// elements along it. Lists are extended with nil elements as required.
+// It is an error if an existing value which is not a map or list as
+// expected blocks the path.
+func setValueForPath(m mxj.Map, path string, v interface{}) error {
+	s, err := steps(path)
+	if err != nil {
+		return err
+	}
+	_, err = setStep(map[string]interface{}(m), "", s, v)
+	return err
+}
+
+// setStep sets v at the steps below node, which is at path, returning
+// node or its replacement if node was created or a list was extended
+func setStep(node interface{}, path string, s []step, v interface{}) (interface{}, error) {
+	if len(s) == 0 {
+		return v, nil
+	}
+	if s[0].index < 0 {
+		m, ok := node.(map[string]interface{})
+		if !ok {
+			if node != nil {
+				return nil, fmt.Errorf("%s has type %T, expected a map", path, node)
+			}
+			m = make(map[string]interface{})
+		}
+		child, err := setStep(m[s[0].key], s[0].path, s[1:], v)
+		if err != nil {
+			return nil, err
+		}
+		m[s[0].key] = child
+		return m, nil
+	}
+	list, ok := node.([]interface{})
+	if !ok && node != nil {
+		return nil, fmt.Errorf("%s has type %T, expected a list", path, node)
+	}
+	for len(list) <= s[0].index {
+		list = append(list, nil)
+	}
+	child, err := setStep(list[s[0].index], s[0].path, s[1:], v)
+	if err != nil {
+		return nil, err
+	}
+	list[s[0].index] = child
+	return list, nil
+}
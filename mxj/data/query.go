@@ -0,0 +1,131 @@
+package data
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/8legd/mapjitsu"
+	"github.com/clbanning/mxj"
+)
+
+// Selection determines which of the values matching a Query are returned.
+type Selection int
+
+const (
+	// All returns every matching value as a []interface{}.
+	All Selection = iota
+	// First returns the first matching value.
+	First
+	// Last returns the last matching value.
+	Last
+)
+
+// Query is a Source returning the values matching Path, which unlike the
+// Path of a Source may match many values e.g.
+//
+//	orders.*.id     the id of every order, * matching any key
+//	..id            every id at any depth
+//	orders..id      every id at any depth below orders
+//
+// Lists along the path are searched element by element, see
+// mxj.Map.ValuesForPath and mxj.Map.ValuesForKey.
+//
+// If Map is nil the input record bound to a mapjitsu.Template is queried.
+// It is a not found error, see mapjitsu.ErrNotFound, if nothing matches.
+type Query struct {
+	Map  mxj.Map
+	Path string
+
+	// Filter optionally keeps only matching maps with the given keys
+	// and values, as mxj "key:value" subkeys e.g. "status:open".
+	Filter []string
+
+	// Where optionally keeps only matching values for which it is true.
+	Where mapjitsu.Predicate
+
+	Select Selection
+}
+
+func (q Query) Value() (interface{}, error) {
+	if q.Map == nil {
+		return nil, fmt.Errorf("%s without a Map can only be read through a Template", q)
+	}
+	return q.query(q.Map)
+}
+
+func (q Query) ValueContext(ctx context.Context) (interface{}, error) {
+	if q.Map != nil {
+		return q.query(q.Map)
+	}
+	m, ok := toMap(mapjitsu.Input(ctx))
+	if !ok {
+		return nil, fmt.Errorf("input record has invalid type %T, expected mxj.Map", mapjitsu.Input(ctx))
+	}
+	return q.query(m)
+}
+
+func (q Query) String() string {
+	return "mxj query " + q.Path
+}
+
+func (q Query) query(m mxj.Map) (interface{}, error) {
+	values, err := q.values(m)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query %s %w", q.Path, err)
+	}
+	if q.Where != nil {
+		var matches []interface{}
+		for _, v := range values {
+			ok, err := q.Where(v)
+			if err != nil {
+				return nil, fmt.Errorf("failed to query %s %w", q.Path, err)
+			}
+			if ok {
+				matches = append(matches, v)
+			}
+		}
+		values = matches
+	}
+	if len(values) == 0 {
+		return nil, mapjitsu.NotFound(fmt.Errorf("failed to query %s %w", q.Path, mxj.PathNotExistError))
+	}
+	switch q.Select {
+	case First:
+		return values[0], nil
+	case Last:
+		return values[len(values)-1], nil
+	}
+	return values, nil
+}
+
+func (q Query) values(m mxj.Map) ([]interface{}, error) {
+	i := strings.Index(q.Path, "..")
+	if i < 0 {
+		return m.ValuesForPath(q.Path, q.Filter...)
+	}
+	key := q.Path[i+2:]
+	if key == "" || strings.Contains(key, ".") {
+		return nil, fmt.Errorf("invalid key %q, expected a single key after ..", key)
+	}
+	if i == 0 {
+		return m.ValuesForKey(key, q.Filter...)
+	}
+	parents, err := m.ValuesForPath(q.Path[:i])
+	if err != nil {
+		return nil, err
+	}
+	var values []interface{}
+	for _, parent := range parents {
+		pm, ok := toMap(parent)
+		if !ok {
+			continue
+		}
+		v, err := pm.ValuesForKey(key, q.Filter...)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, v...)
+	}
+	return values, nil
+}
@@ -0,0 +1,55 @@
+package data
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+
+	"github.com/clbanning/mxj"
+)
+
+// JSONReader is a mapjitsu.RecordReader reading a stream of JSON objects,
+// such as JSON lines, as mxj.Maps.
+type JSONReader struct {
+	r io.Reader
+}
+
+func NewJSONReader(r io.Reader) *JSONReader {
+	return &JSONReader{r: bufio.NewReader(r)}
+}
+
+func (r *JSONReader) Read() (interface{}, error) {
+	m, err := mxj.NewMapJsonReader(r.r)
+	if err != nil {
+		return nil, err
+	}
+	if m == nil {
+		return nil, io.EOF
+	}
+	return m, nil
+}
+
+// JSONWriter is a mapjitsu.RecordWriter writing mxj.Maps as JSON lines.
+type JSONWriter struct {
+	w *bufio.Writer
+}
+
+func NewJSONWriter(w io.Writer) *JSONWriter {
+	return &JSONWriter{w: bufio.NewWriter(w)}
+}
+
+func (w *JSONWriter) Write(record interface{}) error {
+	m, ok := toMap(record)
+	if !ok {
+		return fmt.Errorf("record has invalid type %T, expected mxj.Map", record)
+	}
+	err := m.JsonWriter(w.w)
+	if err != nil {
+		return err
+	}
+	return w.w.WriteByte('\n')
+}
+
+func (w *JSONWriter) Flush() error {
+	return w.w.Flush()
+}
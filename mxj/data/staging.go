@@ -0,0 +1,219 @@
+package data
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/8legd/mapjitsu"
+	"github.com/clbanning/mxj"
+)
+
+// Stage stages a write to be made by Commit, for use by transactional
+// Definitions.
+func (t Target) Stage(ctx context.Context, v interface{}) (mapjitsu.Staged, error) {
+	return &staged{m: t.Map, path: t.Path, set: func() error { return t.SetValue(v) }, undo: undoPath}, nil
+}
+
+// Stage stages a write to the output record, for use by transactional
+// Definitions.
+func (p Path) Stage(ctx context.Context, v interface{}) (mapjitsu.Staged, error) {
+	m, ok := toMap(mapjitsu.Output(ctx))
+	if !ok {
+		return nil, fmt.Errorf("output record has invalid type %T, expected mxj.Map", mapjitsu.Output(ctx))
+	}
+	return &staged{m: m, path: string(p), set: func() error { return p.SetValueContext(ctx, v) }, undo: undoPath}, nil
+}
+
+// Stage stages a write to be made by Commit, for use by transactional
+// Definitions.
+func (t XMLTarget) Stage(ctx context.Context, v interface{}) (mapjitsu.Staged, error) {
+	return &staged{m: t.Map, path: t.Path, set: func() error { return t.SetValue(v) }, undo: undoXMLPath}, nil
+}
+
+// Stage stages a write to the output record, for use by transactional
+// Definitions.
+func (p XMLPath) Stage(ctx context.Context, v interface{}) (mapjitsu.Staged, error) {
+	m, ok := toMap(mapjitsu.Output(ctx))
+	if !ok {
+		return nil, fmt.Errorf("output record has invalid type %T, expected mxj.Map", mapjitsu.Output(ctx))
+	}
+	return &staged{m: m, path: string(p), set: func() error { return p.SetValueContext(ctx, v) }, undo: undoXMLPath}, nil
+}
+
+// staged is a write to a path which can be rolled back to the state of
+// the Map before the write, as recorded by undo
+type staged struct {
+	m         mxj.Map
+	path      string
+	set       func() error
+	undo      func(m mxj.Map, path string) (func(), error)
+	committed bool
+	rollback  func()
+}
+
+func (s *staged) Commit() error {
+	rollback, err := s.undo(s.m, s.path)
+	if err != nil {
+		return err
+	}
+	s.rollback = rollback
+	err = s.set()
+	if err != nil {
+		return err
+	}
+	s.committed = true
+	return nil
+}
+
+func (s *staged) Rollback() error {
+	if !s.committed {
+		return nil
+	}
+	s.committed = false
+	s.rollback()
+	return nil
+}
+
+// location is a value within a map, which can be read and replaced
+type location struct {
+	get func() interface{}
+	set func(v interface{})
+}
+
+// undoPath returns a func restoring m to its current state after path is
+// set. It restores the previous value of path or, at the first step of
+// path which does not exist, removes the map key or the list elements
+// which setting path would create.
+func undoPath(m mxj.Map, path string) (func(), error) {
+	s, err := steps(path)
+	if err != nil {
+		return nil, err
+	}
+	root := map[string]interface{}(m)
+	loc := location{get: func() interface{} { return root }}
+	for _, step := range s {
+		node := loc.get()
+		if node == nil {
+			// a nil value is replaced by a map or list
+			return func() { loc.set(nil) }, nil
+		}
+		if step.index < 0 {
+			parent, ok := node.(map[string]interface{})
+			if !ok {
+				return func() {}, nil // setting path fails
+			}
+			key := step.key
+			if _, ok := parent[key]; !ok {
+				return func() { delete(parent, key) }, nil
+			}
+			loc = location{
+				get: func() interface{} { return parent[key] },
+				set: func(v interface{}) { parent[key] = v },
+			}
+			continue
+		}
+		list, ok := node.([]interface{})
+		if !ok {
+			return func() {}, nil // setting path fails
+		}
+		if step.index >= len(list) {
+			n, listLoc := len(list), loc
+			return func() {
+				if list, ok := listLoc.get().([]interface{}); ok && len(list) > n {
+					listLoc.set(list[:n])
+				}
+			}, nil
+		}
+		index := step.index
+		loc = location{
+			get: func() interface{} { return list[index] },
+			set: func(v interface{}) { list[index] = v },
+		}
+	}
+	previous := loc.get()
+	return func() { loc.set(previous) }, nil
+}
+
+// undoXMLPath returns a func restoring the XML document m to its current
+// state after path is set with XMLTarget. Setting path only changes the
+// existing elements along it, with their attributes, so their contents
+// are restored.
+func undoXMLPath(m mxj.Map, path string) (func(), error) {
+	s, err := steps(path)
+	if err != nil {
+		return nil, err
+	}
+	var snapshots []snapshot
+	element := map[string]interface{}(m)
+	for i := 0; element != nil; i++ {
+		snapshots = append(snapshots, snapshotOf(element))
+		attributes, _ := element["#attr"].(map[string]interface{})
+		if attributes != nil {
+			snapshots = append(snapshots, snapshotOf(attributes))
+			for _, attribute := range attributes {
+				if attribute, ok := attribute.(map[string]interface{}); ok {
+					snapshots = append(snapshots, snapshotOf(attribute))
+				}
+			}
+		}
+		if i >= len(s) || strings.HasPrefix(s[i].key, "@") || s[i].key == "#text" {
+			break
+		}
+		key, index := s[i].key, -1
+		if i+1 < len(s) && s[i+1].index >= 0 {
+			index = s[i+1].index
+			i++
+		}
+		element = existingXMLElement(element, key, index)
+	}
+	return func() {
+		for _, snapshot := range snapshots {
+			snapshot.restore()
+		}
+	}, nil
+}
+
+// existingXMLElement returns the child element key of parent, or if
+// index is not negative the indexed element of its list, or nil if it
+// does not exist as an element (see xmlElement)
+func existingXMLElement(parent map[string]interface{}, key string, index int) map[string]interface{} {
+	list, isList := parent[key].([]interface{})
+	if !isList {
+		list = []interface{}{parent[key]}
+	}
+	if index < 0 {
+		if isList {
+			return nil
+		}
+		index = 0
+	}
+	if index >= len(list) {
+		return nil
+	}
+	child, _ := list[index].(map[string]interface{})
+	return child
+}
+
+// snapshot records the contents of a map to be restored
+type snapshot struct {
+	m        map[string]interface{}
+	contents map[string]interface{}
+}
+
+func snapshotOf(m map[string]interface{}) snapshot {
+	contents := make(map[string]interface{}, len(m))
+	for key, value := range m {
+		contents[key] = value
+	}
+	return snapshot{m: m, contents: contents}
+}
+
+func (s snapshot) restore() {
+	for key := range s.m {
+		delete(s.m, key)
+	}
+	for key, value := range s.contents {
+		s.m[key] = value
+	}
+}
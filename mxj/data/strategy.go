@@ -0,0 +1,120 @@
+package data
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrPresent is returned by a Target with the ErrorIfPresent Strategy
+// when its path already has a value.
+var ErrPresent = errors.New("value already present")
+
+// Strategy determines how a Target writes to a path which already has a
+// value, which may be null.
+type Strategy int
+
+const (
+	// Overwrite replaces any existing value.
+	Overwrite Strategy = iota
+	// KeepExisting leaves any existing value unchanged.
+	KeepExisting
+	// ErrorIfPresent fails with ErrPresent if there is an existing value.
+	ErrorIfPresent
+	// DeepMerge merges a map into an existing map, recursively merging
+	// the maps they share and otherwise replacing existing values.
+	DeepMerge
+	// Append appends to an existing list, or creates one. A list is
+	// appended element by element.
+	Append
+)
+
+func (s Strategy) String() string {
+	switch s {
+	case Overwrite:
+		return "overwrite"
+	case KeepExisting:
+		return "keep existing"
+	case ErrorIfPresent:
+		return "error if present"
+	case DeepMerge:
+		return "deep merge"
+	case Append:
+		return "append"
+	}
+	return "unknown strategy"
+}
+
+// apply returns the value to write given the existing value, if present,
+// or false if nothing should be written
+func (s Strategy) apply(existing interface{}, present bool, v interface{}) (interface{}, bool, error) {
+	switch s {
+	case KeepExisting:
+		return v, !present, nil
+	case ErrorIfPresent:
+		if present {
+			return nil, false, ErrPresent
+		}
+	case DeepMerge:
+		if present && existing != nil {
+			// merge into a copy as the existing map may be held elsewhere,
+			// such as to roll back a transaction
+			merged, err := merge(copyMaps(existing), v)
+			return merged, err == nil, err
+		}
+	case Append:
+		var list []interface{}
+		if present && existing != nil {
+			l, ok := existing.([]interface{})
+			if !ok {
+				return nil, false, fmt.Errorf("existing value has type %T, expected a list", existing)
+			}
+			// copy as the existing list may share its array with another
+			list = append(make([]interface{}, 0, len(l)+1), l...)
+		}
+		if values, ok := v.([]interface{}); ok {
+			return append(list, values...), true, nil
+		}
+		return append(list, v), true, nil
+	}
+	return v, true, nil
+}
+
+// merge merges v into existing, which must both be maps, modifying existing
+func merge(existing interface{}, v interface{}) (interface{}, error) {
+	to, ok := toMap(existing)
+	if !ok {
+		return nil, fmt.Errorf("existing value has type %T, expected a map", existing)
+	}
+	from, ok := toMap(v)
+	if !ok {
+		return nil, fmt.Errorf("value has invalid type %T, expected a map", v)
+	}
+	for key, value := range from {
+		if _, ok := toMap(to[key]); ok {
+			if _, ok := toMap(value); ok {
+				merged, err := merge(to[key], value)
+				if err != nil {
+					return nil, err
+				}
+				to[key] = merged
+				continue
+			}
+		}
+		to[key] = copyMaps(value)
+	}
+	return map[string]interface{}(to), nil
+}
+
+// copyMaps returns v with any maps copied, so merging into them later
+// does not modify the value v was read from
+func copyMaps(v interface{}) interface{} {
+	m, ok := toMap(v)
+	if !ok {
+		return v
+	}
+	c := make(map[string]interface{}, len(m))
+	for key, value := range m {
+		c[key] = copyMaps(value)
+	}
+	return c
+}
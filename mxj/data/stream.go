@@ -0,0 +1,258 @@
+package data
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/clbanning/mxj"
+)
+
+// XMLElementReader is a mapjitsu.RecordReader reading the elements at a
+// repeating path of an XML document one at a time, e.g. Export.Record for
+// each Record element of
+//
+//	<Export><Record>...</Record><Record>...</Record></Export>
+//
+// Only the element being read is held in memory, so documents of any size
+// can be read. Each element is read as an mxj.Map, as decoded by
+// mxj.NewMapXmlSeq, keyed by the element name for use with XMLSource and
+// XMLPath. The path of an element with a namespace prefix includes it e.g.
+// soap:Body.Record.
+//
+// XMLElementReader implements mapjitsu.OffsetReader, so errors from a
+// mapjitsu.Runner include the position of the element in the document.
+type XMLElementReader struct {
+	d      *xml.Decoder
+	input  *recorder
+	path   string
+	stack  []string
+	offset int64
+}
+
+func NewXMLElementReader(r io.Reader, path string) *XMLElementReader {
+	input := &recorder{r: r}
+	return &XMLElementReader{d: xml.NewDecoder(input), input: input, path: path}
+}
+
+func (r *XMLElementReader) Read() (interface{}, error) {
+	for {
+		offset := r.d.InputOffset()
+		r.input.discard(offset)
+		token, err := r.d.RawToken()
+		if err == io.EOF {
+			if len(r.stack) > 0 {
+				r.offset = offset
+				return nil, fmt.Errorf("failed to read xml at offset %d %w", offset, io.ErrUnexpectedEOF)
+			}
+			return nil, io.EOF
+		}
+		if err != nil {
+			r.offset = offset
+			return nil, fmt.Errorf("failed to read xml at offset %d %w", offset, err)
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			r.stack = append(r.stack, xmlName(t.Name))
+			if strings.Join(r.stack, ".") == r.path {
+				r.offset = offset
+				return r.element(offset)
+			}
+		case xml.EndElement:
+			if len(r.stack) > 0 {
+				r.stack = r.stack[:len(r.stack)-1]
+			}
+		}
+	}
+}
+
+// Offset returns the position of the element last read, or which failed
+// to be read.
+func (r *XMLElementReader) Offset() int64 {
+	return r.offset
+}
+
+// element reads the rest of the element started at offset
+func (r *XMLElementReader) element(offset int64) (interface{}, error) {
+	r.stack = r.stack[:len(r.stack)-1]
+	for depth := 1; depth > 0; {
+		token, err := r.d.RawToken()
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s at offset %d %w", r.path, offset, err)
+		}
+		switch token.(type) {
+		case xml.StartElement:
+			depth++
+		case xml.EndElement:
+			depth--
+		}
+	}
+	m, err := mxj.NewMapXmlSeqReader(bytes.NewReader(r.input.bytes(offset, r.d.InputOffset())))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s at offset %d %w", r.path, offset, err)
+	}
+	return m, nil
+}
+
+func xmlName(name xml.Name) string {
+	if name.Space != "" {
+		return name.Space + ":" + name.Local
+	}
+	return name.Local
+}
+
+// recorder records the input read from r, from the offset last discarded,
+// so the bytes of an element can be recovered after it has been parsed
+type recorder struct {
+	r    io.Reader
+	buf  []byte
+	base int64 // offset of buf[0]
+}
+
+func (r *recorder) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.buf = append(r.buf, p[:n]...)
+	return n, err
+}
+
+// discard drops the input recorded before offset
+func (r *recorder) discard(offset int64) {
+	if n := offset - r.base; n > 0 {
+		r.buf = append(r.buf[:0], r.buf[n:]...)
+		r.base = offset
+	}
+}
+
+func (r *recorder) bytes(from int64, to int64) []byte {
+	return r.buf[from-r.base : to-r.base]
+}
+
+// JSONElementReader is a mapjitsu.RecordReader reading the objects of a
+// list at a path of a JSON document one at a time, e.g. export.records
+// for each record of
+//
+//	{"export": {"records": [{...}, {...}]}}
+//
+// or the objects of a list at the root of the document for an empty path.
+// Only the object being read is held in memory, so documents of any size
+// can be read. Each object is read as an mxj.Map, as decoded by
+// mxj.NewMapJson.
+//
+// JSONElementReader implements mapjitsu.OffsetReader, so errors from a
+// mapjitsu.Runner include the position of the object in the document.
+type JSONElementReader struct {
+	d      *json.Decoder
+	path   string
+	stack  []jsonFrame
+	offset int64
+}
+
+// jsonFrame is an object or list being read at path
+type jsonFrame struct {
+	list   bool
+	path   string
+	key    string // key of the current value of an object
+	hasKey bool
+}
+
+func NewJSONElementReader(r io.Reader, path string) *JSONElementReader {
+	return &JSONElementReader{d: json.NewDecoder(r), path: path}
+}
+
+func (r *JSONElementReader) Read() (interface{}, error) {
+	for {
+		if n := len(r.stack); n > 0 && r.stack[n-1].list && r.stack[n-1].path == r.path && r.d.More() {
+			return r.element()
+		}
+		offset := r.d.InputOffset()
+		token, err := r.d.Token()
+		if err == io.EOF {
+			if len(r.stack) > 0 {
+				r.offset = offset
+				return nil, fmt.Errorf("failed to read json at offset %d %w", offset, io.ErrUnexpectedEOF)
+			}
+			return nil, io.EOF
+		}
+		if err != nil {
+			r.offset = offset
+			return nil, fmt.Errorf("failed to read json at offset %d %w", offset, err)
+		}
+
+		// a key of an object
+		if n := len(r.stack); n > 0 && !r.stack[n-1].list && !r.stack[n-1].hasKey {
+			if key, ok := token.(string); ok {
+				r.stack[n-1].key = key
+				r.stack[n-1].hasKey = true
+				continue
+			}
+		}
+
+		// otherwise a value, or the end of an object or list
+		path := r.valuePath()
+		if n := len(r.stack); n > 0 && !r.stack[n-1].list {
+			r.stack[n-1].hasKey = false
+		}
+		switch token {
+		case json.Delim('{'):
+			r.stack = append(r.stack, jsonFrame{path: path})
+		case json.Delim('['):
+			r.stack = append(r.stack, jsonFrame{list: true, path: path})
+		case json.Delim('}'), json.Delim(']'):
+			r.stack = r.stack[:len(r.stack)-1]
+		default:
+			if path == r.path {
+				r.offset = offset
+				return nil, fmt.Errorf("failed to read %s at offset %d, expected a list", r.path, offset)
+			}
+		}
+	}
+}
+
+// Offset returns the position of the object last read, or which failed
+// to be read.
+func (r *JSONElementReader) Offset() int64 {
+	return r.offset
+}
+
+// element reads the next object of the list at the path
+func (r *JSONElementReader) element() (interface{}, error) {
+	r.offset = r.d.InputOffset()
+	var raw json.RawMessage
+	err := r.d.Decode(&raw)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s at offset %d %w", r.path, r.offset, err)
+	}
+	// the offset of the object itself, rather than any separator before it
+	r.offset = r.d.InputOffset() - int64(len(raw))
+	if len(raw) == 0 || raw[0] != '{' {
+		return nil, fmt.Errorf("failed to read %s at offset %d, expected an object", r.path, r.offset)
+	}
+	m, err := mxj.NewMapJsonReader(bytes.NewReader(raw))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s at offset %d %w", r.path, r.offset, err)
+	}
+	return m, nil
+}
+
+// valuePath returns the path of the value being read, lists being
+// transparent as for mxj paths
+func (r *JSONElementReader) valuePath() string {
+	n := len(r.stack)
+	if n == 0 {
+		return ""
+	}
+	top := r.stack[n-1]
+	if top.list || !top.hasKey {
+		return top.path
+	}
+	if top.path == "" {
+		return top.key
+	}
+	return top.path + "." + top.key
+}
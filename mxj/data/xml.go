@@ -0,0 +1,388 @@
+package data
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/8legd/mapjitsu"
+	"github.com/clbanning/mxj"
+)
+
+// XMLSource reads the value at Path of Map, an XML document decoded with
+// mxj.NewMapXmlSeq which records the attributes and order of elements.
+//
+// Paths start at the root element and are separated by dots. Elements
+// with a namespace prefix keep it e.g. soap:Envelope.soap:Body, repeated
+// elements are indexed e.g. Items.Item[1], an attribute is the last part
+// of a path prefixed with @ e.g. Items.Item[1].@sku and the text of an
+// element is its value or, for an element which also has child elements,
+// #text e.g. Note.#text.
+//
+// The value of an element with child elements is its map, and of a
+// repeated element without an index the list of its maps, e.g. for
+// mapjitsu.ForEach with XMLPath.
+type XMLSource struct {
+	Map  mxj.Map
+	Path string
+}
+
+func (s XMLSource) Value() (interface{}, error) {
+	v, err := xmlValue(s.Map, s.Path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to return %s %w", s.Path, err)
+	}
+	return v, nil
+}
+
+func (s XMLSource) String() string {
+	return "xml path " + s.Path
+}
+
+// AsTarget returns the Target for the same path, see mapjitsu.Definition.Reverse.
+func (s XMLSource) AsTarget() mapjitsu.Target {
+	return XMLTarget{Map: s.Map, Path: s.Path}
+}
+
+// XMLTarget writes values at Path of Map, an XML document to be encoded
+// with EncodeXML, see XMLSource for the syntax of Path. Any missing
+// elements along Path are created, after any existing elements, so new
+// elements are encoded in the order they are written. Values are written
+// as text using their default format, maps as the attributes and child
+// elements of an element and lists as repeated elements e.g. from
+// mapjitsu.ForEach.
+//
+// Text is held unescaped in the Map, as XMLSource returns it, and is
+// escaped by EncodeXML.
+type XMLTarget struct {
+	Map  mxj.Map
+	Path string
+}
+
+func (t XMLTarget) SetValue(v interface{}) error {
+	err := setXMLValue(t.Map, t.Path, v)
+	if err != nil {
+		return fmt.Errorf("failed to set %s %w", t.Path, err)
+	}
+	return nil
+}
+
+func (t XMLTarget) String() string {
+	return "xml path " + t.Path
+}
+
+// AsSource returns the Source for the same path, see mapjitsu.Definition.Reverse.
+func (t XMLTarget) AsSource() mapjitsu.Source {
+	return XMLSource{Map: t.Map, Path: t.Path}
+}
+
+// Container identifies the Map written to, allowing concurrent writes
+// to be serialised per Map.
+func (t XMLTarget) Container() interface{} {
+	return Target{Map: t.Map}.Container()
+}
+
+// XMLPath refers to a path within XML records bound to a
+// mapjitsu.Template, as Path does for other records. See XMLSource for
+// the syntax of paths, which within an element mapped by
+// mapjitsu.ForEach are relative to the element.
+type XMLPath string
+
+// Value returns an error as an XMLPath can only be read through a Template.
+func (p XMLPath) Value() (interface{}, error) {
+	return nil, fmt.Errorf("%s can only be read through a Template", p)
+}
+
+func (p XMLPath) ValueContext(ctx context.Context) (interface{}, error) {
+	m, ok := toMap(mapjitsu.Input(ctx))
+	if !ok {
+		return nil, fmt.Errorf("input record has invalid type %T, expected mxj.Map", mapjitsu.Input(ctx))
+	}
+	return XMLSource{Map: m, Path: string(p)}.Value()
+}
+
+// SetValue returns an error as an XMLPath can only be written through a Template.
+func (p XMLPath) SetValue(v interface{}) error {
+	return fmt.Errorf("%s can only be written through a Template", p)
+}
+
+func (p XMLPath) SetValueContext(ctx context.Context, v interface{}) error {
+	m, ok := toMap(mapjitsu.Output(ctx))
+	if !ok {
+		return fmt.Errorf("output record has invalid type %T, expected mxj.Map", mapjitsu.Output(ctx))
+	}
+	return XMLTarget{Map: m, Path: string(p)}.SetValue(v)
+}
+
+func (p XMLPath) String() string {
+	return "xml path " + string(p)
+}
+
+// xmlValue returns the value at path of the XML document m
+func xmlValue(m mxj.Map, path string) (interface{}, error) {
+	s, err := steps(path)
+	if err != nil {
+		return nil, err
+	}
+	var node interface{} = map[string]interface{}(m)
+	for i, step := range s {
+		last := i == len(s)-1
+		if step.index >= 0 {
+			list, ok := node.([]interface{})
+			if !ok {
+				// a single element is the first of its list
+				list = []interface{}{node}
+			}
+			if step.index >= len(list) {
+				return nil, mapjitsu.NotFound(fmt.Errorf("%s has %d elements", s[i-1].path, len(list)))
+			}
+			node = list[step.index]
+			continue
+		}
+		if _, ok := node.([]interface{}); ok {
+			return nil, fmt.Errorf("%s is repeated, expected an index", s[i-1].path)
+		}
+		element, ok := node.(map[string]interface{})
+		if !ok {
+			return nil, mapjitsu.NotFound(fmt.Errorf("%s has no child elements or attributes", s[i-1].path))
+		}
+		if strings.HasPrefix(step.key, "@") {
+			if !last {
+				return nil, fmt.Errorf("invalid path, attribute %s is not last", step.key)
+			}
+			attributes, _ := element["#attr"].(map[string]interface{})
+			attribute, ok := attributes[step.key[1:]].(map[string]interface{})
+			if !ok {
+				return nil, mapjitsu.NotFound(fmt.Errorf("%s does not exist", step.path))
+			}
+			return attribute["#text"], nil
+		}
+		child, ok := element[step.key]
+		if !ok {
+			return nil, mapjitsu.NotFound(fmt.Errorf("%s does not exist", step.path))
+		}
+		node = child
+	}
+	return xmlText(node), nil
+}
+
+// xmlText returns the text of a simple element, or the element unchanged
+func xmlText(node interface{}) interface{} {
+	element, ok := node.(map[string]interface{})
+	if !ok {
+		return node
+	}
+	text, ok := element["#text"]
+	if !ok {
+		return node
+	}
+	for key := range element {
+		if key != "#text" && key != "#seq" && key != "#attr" {
+			return node
+		}
+	}
+	return text
+}
+
+// setXMLValue sets v at path of the XML document m, creating any
+// missing elements
+func setXMLValue(m mxj.Map, path string, v interface{}) error {
+	s, err := steps(path)
+	if err != nil {
+		return err
+	}
+	element := map[string]interface{}(m)
+	for i := 0; i < len(s); i++ {
+		step := s[i]
+		last := i == len(s)-1
+		if strings.HasPrefix(step.key, "@") {
+			if !last {
+				return fmt.Errorf("invalid path, attribute %s is not last", step.key)
+			}
+			attributes, ok := element["#attr"].(map[string]interface{})
+			if !ok {
+				attributes = make(map[string]interface{})
+				element["#attr"] = attributes
+			}
+			name := step.key[1:]
+			attribute, ok := attributes[name].(map[string]interface{})
+			if !ok {
+				attribute = map[string]interface{}{"#seq": len(attributes)}
+				attributes[name] = attribute
+			}
+			attribute["#text"] = xmlString(v)
+			return nil
+		}
+		if step.key == "#text" {
+			if !last {
+				return fmt.Errorf("invalid path, #text is not last")
+			}
+			element["#text"] = xmlString(v)
+			return nil
+		}
+
+		if values, ok := v.([]interface{}); ok && last {
+			// a list is written as repeated elements
+			seq := nextSeq(element)
+			elements := make([]interface{}, len(values))
+			for n, value := range values {
+				child := map[string]interface{}{"#seq": seq + n}
+				setXMLElement(child, value)
+				elements[n] = child
+			}
+			element[step.key] = elements
+			return nil
+		}
+
+		// the element at step, or the indexed element of its list
+		index := -1
+		if i+1 < len(s) && s[i+1].index >= 0 {
+			index = s[i+1].index
+			i++
+			last = i == len(s)-1
+		}
+		child, err := xmlElement(element, step.key, index, s[i].path)
+		if err != nil {
+			return err
+		}
+		if last {
+			setXMLElement(child, v)
+			return nil
+		}
+		element = child
+	}
+	return nil
+}
+
+// setXMLElement sets the content of element to v, the text of a simple
+// element or the map of an element with attributes or child elements
+func setXMLElement(element map[string]interface{}, v interface{}) {
+	value, ok := toMap(v)
+	if !ok {
+		element["#text"] = xmlString(v)
+		return
+	}
+	for key, value := range value {
+		if key != "#seq" {
+			element[key] = copyMaps(value)
+		}
+	}
+}
+
+// xmlElement returns the child element key of parent, or if index is not
+// negative the indexed element of its list, creating it if required. Only
+// the element after the last of a list can be created.
+func xmlElement(parent map[string]interface{}, key string, index int, path string) (map[string]interface{}, error) {
+	existing, ok := parent[key]
+	if !ok || existing == "" {
+		if index > 0 {
+			return nil, fmt.Errorf("%s can not be created, %s has no elements", path, key)
+		}
+		child := map[string]interface{}{"#seq": nextSeq(parent)}
+		parent[key] = child
+		return child, nil
+	}
+	if s, ok := existing.(string); ok {
+		// a simple element decoded without sequence numbers
+		existing = map[string]interface{}{"#text": s, "#seq": nextSeq(parent)}
+		parent[key] = existing
+	}
+	list, isList := existing.([]interface{})
+	if !isList {
+		list = []interface{}{existing}
+	}
+	if index < 0 {
+		if isList {
+			return nil, fmt.Errorf("%s is repeated, expected an index", path)
+		}
+		index = 0
+	}
+	if index > len(list) {
+		return nil, fmt.Errorf("%s can not be created, %s has %d elements", path, key, len(list))
+	}
+	if index == len(list) {
+		child := map[string]interface{}{"#seq": nextSeq(parent)}
+		parent[key] = append(list, child)
+		return child, nil
+	}
+	child, ok := list[index].(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s has type %T, expected an element", path, list[index])
+	}
+	return child, nil
+}
+
+// nextSeq returns the sequence number to encode a new child element of
+// parent after its existing elements
+func nextSeq(parent map[string]interface{}) int {
+	next := 0
+	seq := func(v interface{}) {
+		if element, ok := v.(map[string]interface{}); ok {
+			if n, ok := element["#seq"].(int); ok && n >= next {
+				next = n + 1
+			}
+		}
+	}
+	for key, v := range parent {
+		if key == "#attr" {
+			continue
+		}
+		if list, ok := v.([]interface{}); ok {
+			for _, element := range list {
+				seq(element)
+			}
+			continue
+		}
+		seq(v)
+	}
+	return next
+}
+
+// xmlString returns v as text
+func xmlString(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	}
+	return fmt.Sprintf("%v", v)
+}
+
+// EncodeXML encodes m, an XML document decoded with mxj.NewMapXmlSeq or
+// written by XMLTarget, with mxj.Map.XmlSeq escaping its text and
+// attribute values. mxj only escapes values once mxj.XMLEscapeChars is
+// set, use m.XmlSeq instead if it is.
+func EncodeXML(m mxj.Map, rootTag ...string) ([]byte, error) {
+	escaped, _ := escapeXML(map[string]interface{}(m)).(map[string]interface{})
+	return mxj.Map(escaped).XmlSeq(rootTag...)
+}
+
+var xmlEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;", "'", "&apos;")
+
+// escapeXML returns a copy of v with its text escaped, other than the
+// text of comments, directives and processing instructions
+func escapeXML(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		escaped := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			switch key {
+			case "#comment", "#directive", "#procinst":
+				escaped[key] = value
+			default:
+				escaped[key] = escapeXML(value)
+			}
+		}
+		return escaped
+	case []interface{}:
+		escaped := make([]interface{}, len(v))
+		for i, value := range v {
+			escaped[i] = escapeXML(value)
+		}
+		return escaped
+	case string:
+		return xmlEscaper.Replace(v)
+	}
+	return v
+}
@@ -0,0 +1,53 @@
+package mapjitsu
+
+import (
+	"context"
+	"errors"
+)
+
+// Optional is a Source which returns Default, rather than an error, when
+// Source reports that its value is absent (see ErrNotFound). A value which
+// is present but null is returned as is.
+type Optional struct {
+	Source  Source
+	Default interface{}
+}
+
+func (o Optional) Value() (interface{}, error) {
+	return o.ValueContext(context.Background())
+}
+
+func (o Optional) ValueContext(ctx context.Context) (interface{}, error) {
+	v, err := value(ctx, o.Source)
+	if notFound(err) {
+		return o.Default, nil
+	}
+	return v, err
+}
+
+func (o Optional) String() string {
+	return "optional " + describe(o.Source)
+}
+
+// ReadsVars returns the variables read by Source.
+func (o Optional) ReadsVars() []string {
+	if r, ok := o.Source.(VarReader); ok {
+		return r.ReadsVars()
+	}
+	return nil
+}
+
+// Check checks Source if it is a Checker.
+func (o Optional) Check() error {
+	if c, ok := o.Source.(Checker); ok {
+		return c.Check()
+	}
+	return nil
+}
+
+// notFound reports whether err means a value is absent, a value missing
+// from an element of a ForEach is a failure of the ForEach instead
+func notFound(err error) bool {
+	var elementError *ElementError
+	return errors.Is(err, ErrNotFound) && !errors.As(err, &elementError)
+}
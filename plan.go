@@ -0,0 +1,53 @@
+package mapjitsu
+
+import (
+	"context"
+	"fmt"
+)
+
+// PlannedWrite describes a value which would be written to a Target.
+type PlannedWrite struct {
+	Index  int
+	Name   string
+	Target string // a description of the Target
+	Value  interface{}
+	Type   string // the Go type of Value
+}
+
+// Plan evaluates every Source and Pipeline as Apply would but, rather than
+// setting Targets, returns the writes that would be made in the order they
+// would be made. Var targets are still set so dependent Mappings can be
+// evaluated but are not included in the plan.
+//
+// Errors are returned as for Apply along with the writes planned so far.
+func (d Definition) Plan() ([]PlannedWrite, error) {
+	return d.PlanContext(context.Background())
+}
+
+// PlanContext is Plan with a context, see ApplyContext.
+func (d Definition) PlanContext(ctx context.Context) ([]PlannedWrite, error) {
+	var plan []PlannedWrite
+	err := d.run(ctx, func(m Mapping, ctx context.Context, index int, v interface{}) error {
+		if _, ok := m.Target.(VarWriter); ok {
+			return m.set(ctx, index, v)
+		}
+		plan = append(plan, PlannedWrite{
+			Index:  index,
+			Name:   m.Name,
+			Target: describe(m.Target),
+			Value:  v,
+			Type:   fmt.Sprintf("%T", v),
+		})
+		return nil
+	})
+	return plan, err
+}
+
+// describe returns a description of a Source or Target, using its String
+// method if it has one
+func describe(v interface{}) string {
+	if s, ok := v.(fmt.Stringer); ok {
+		return s.String()
+	}
+	return fmt.Sprintf("%T", v)
+}
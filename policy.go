@@ -0,0 +1,76 @@
+package mapjitsu
+
+// ErrorPolicy determines how the failure of a Mapping is handled. It
+// applies to errors from the Source, When condition, Pipeline and Target
+// of any Mapping but not to validation failures, which are always
+// reported in a ValidationReport. Errors recovered by the ErrorPolicy are
+// not passed to Hooks.OnError.
+type ErrorPolicy int
+
+const (
+	// FailOnError fails the Definition, or with CollectErrors records
+	// the error and continues.
+	FailOnError ErrorPolicy = iota
+	// SkipOnError ignores the error, leaving the Target unchanged. For a
+	// Transactional Definition this includes errors committing the write.
+	SkipOnError
+	// DefaultOnError writes the Mapping Default to the Target when the
+	// Source, When condition or Pipeline fails.
+	DefaultOnError
+	// CollectOnError records the error and continues, as for a Definition
+	// with CollectErrors.
+	CollectOnError
+)
+
+func (p ErrorPolicy) String() string {
+	switch p {
+	case FailOnError:
+		return "fail"
+	case SkipOnError:
+		return "skip"
+	case DefaultOnError:
+		return "default"
+	case CollectOnError:
+		return "collect"
+	}
+	return "unknown error policy"
+}
+
+// recover applies the ErrorPolicy to an error evaluating the Mapping
+func (m Mapping) recover(v interface{}, ok bool, err error) (interface{}, bool, error) {
+	if !m.recovers(err) {
+		return v, ok, err
+	}
+	if m.OnError == DefaultOnError {
+		return m.Default, true, nil
+	}
+	return nil, false, nil
+}
+
+// recovers reports whether the ErrorPolicy recovers from err, which
+// DefaultOnError does for any error except from the Target
+func (m Mapping) recovers(err error) bool {
+	if isValidationError(err) {
+		return false
+	}
+	switch m.OnError {
+	case SkipOnError:
+		return true
+	case DefaultOnError:
+		mappingError, ok := err.(*MappingError)
+		return !ok || mappingError.Stage != StageTarget
+	}
+	return false
+}
+
+// collects reports whether err should be recorded, rather than stopping
+// the Definition
+func (d Definition) collects(err error) bool {
+	if d.CollectErrors {
+		return true
+	}
+	if mappingError, ok := err.(*MappingError); ok && mappingError.Index < len(d.Mappings) {
+		return d.Mappings[mappingError.Index].OnError == CollectOnError
+	}
+	return false
+}
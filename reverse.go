@@ -0,0 +1,94 @@
+package mapjitsu
+
+import (
+	"fmt"
+)
+
+// ReversibleSource is an optional interface implemented by Sources which
+// can be written in the reverse direction, returning the Target for the
+// same value. Sources which are also Targets, such as Var, need not
+// implement it.
+type ReversibleSource interface {
+	AsTarget() Target
+}
+
+// ReversibleTarget is an optional interface implemented by Targets which
+// can be read in the reverse direction, returning the Source for the same
+// value. Targets which are also Sources, such as Var, need not implement it.
+type ReversibleTarget interface {
+	AsSource() Source
+}
+
+// Reverse returns the Definition mapping in the opposite direction, with
+// the Source and Target of each Mapping swapped and the Transform
+// Pipeline replaced by its Inverse applied in reverse order.
+//
+// Every step of a Transform must have an Inverse. Conditional Mappings
+// and the DefaultOnError policy can not be reversed, and Validate is not
+// carried over as it checks values written in the original direction.
+// Any Mappings which can not be reversed are returned as Errors.
+func (d Definition) Reverse() (Definition, error) {
+	reversed := d
+	reversed.Mappings = make([]Mapping, len(d.Mappings))
+	var errs Errors
+	for i, m := range d.Mappings {
+		r, err := m.reverse(i)
+		if err != nil {
+			errs = append(errs, err...)
+			continue
+		}
+		reversed.Mappings[i] = r
+	}
+	if len(errs) > 0 {
+		return Definition{}, errs
+	}
+	return reversed, nil
+}
+
+func (m Mapping) reverse(index int) (Mapping, Errors) {
+	var errs Errors
+	if m.When != nil || m.WhenSource != nil {
+		errs = append(errs, m.error(index, StageCondition, 0, nil, fmt.Errorf("conditional mappings can not be reversed")))
+	}
+	if m.OnError == DefaultOnError {
+		errs = append(errs, m.error(index, StageSource, 0, nil, fmt.Errorf("mappings using %s can not be reversed", DefaultOnError)))
+	}
+
+	r := Mapping{Name: m.Name, OnError: m.OnError, Optional: m.Optional}
+	switch s := m.Source.(type) {
+	case ReversibleSource:
+		r.Target = s.AsTarget()
+	case Target:
+		r.Target = s
+	default:
+		errs = append(errs, m.error(index, StageSource, 0, nil, fmt.Errorf("source %s can not be reversed", describe(m.Source))))
+	}
+	switch t := m.Target.(type) {
+	case ReversibleTarget:
+		r.Source = t.AsSource()
+	case Source:
+		r.Source = t
+	default:
+		errs = append(errs, m.error(index, StageTarget, 0, nil, fmt.Errorf("target %s can not be reversed", describe(m.Target))))
+	}
+
+	if len(m.Inverse) > len(m.Transform) {
+		errs = append(errs, m.error(index, StageTransform, 0, nil, fmt.Errorf("expected at most %d inverse steps, got %d", len(m.Transform), len(m.Inverse))))
+	}
+	for step := len(m.Transform) - 1; step >= 0; step-- {
+		var inverse func(interface{}) (interface{}, error)
+		if step < len(m.Inverse) {
+			inverse = m.Inverse[step]
+		}
+		if inverse == nil {
+			errs = append(errs, m.error(index, StageTransform, step, nil, fmt.Errorf("not invertible, no inverse declared")))
+			continue
+		}
+		r.Transform = append(r.Transform, inverse)
+		r.Inverse = append(r.Inverse, m.Transform[step])
+	}
+	if len(errs) > 0 {
+		return Mapping{}, errs
+	}
+	return r, nil
+}
@@ -0,0 +1,191 @@
+package spec
+
+import (
+	"fmt"
+
+	"github.com/8legd/mapjitsu"
+)
+
+// Args holds the arguments given to an adapter.
+type Args map[string]interface{}
+
+// String returns the required string argument key.
+func (a Args) String(key string) (string, error) {
+	v, ok := a[key]
+	if !ok {
+		return "", fmt.Errorf("missing %s", key)
+	}
+	s, ok := v.(string)
+	if !ok {
+		return "", fmt.Errorf("%s has invalid type %T, expected string", key, v)
+	}
+	return s, nil
+}
+
+// Int returns the required integer argument key.
+func (a Args) Int(key string) (int, error) {
+	v, ok := a[key]
+	if !ok {
+		return 0, fmt.Errorf("missing %s", key)
+	}
+	i, ok := v.(int)
+	if !ok {
+		return 0, fmt.Errorf("%s has invalid type %T, expected integer", key, v)
+	}
+	return i, nil
+}
+
+// SourceFactory returns the Source described by args.
+type SourceFactory func(args Args) (mapjitsu.Source, error)
+
+// TargetFactory returns the Target described by args.
+type TargetFactory func(args Args) (mapjitsu.Target, error)
+
+// TransformFactory returns the Pipeline function described by args.
+type TransformFactory func(args []interface{}) (func(interface{}) (interface{}, error), error)
+
+var errorPolicies = map[string]mapjitsu.ErrorPolicy{
+	"fail":    mapjitsu.FailOnError,
+	"skip":    mapjitsu.SkipOnError,
+	"default": mapjitsu.DefaultOnError,
+	"collect": mapjitsu.CollectOnError,
+}
+
+// Registry resolves the adapters and transforms named in a specification.
+//
+// Sources and Targets are usually registered with factories bound to the
+// data being mapped e.g.
+//
+//	registry.RegisterSource("input", func(args spec.Args) (mapjitsu.Source, error) {
+//		path, err := args.String("path")
+//		return mxjData.Source{Map: input, Path: path}, err
+//	})
+type Registry struct {
+	sources    map[string]SourceFactory
+	targets    map[string]TargetFactory
+	transforms map[string]TransformFactory
+}
+
+// NewRegistry returns a Registry containing the builtin adapters and transforms.
+//
+// The builtin "var" adapter reads or writes the mapjitsu.Var given by name
+// and the builtin "const" source adapter returns value.
+//
+// The builtin transforms are those of the transforms package: string, int,
+// float, bool, trim (with an optional cutset), upper, lower, default,
+// default_if_empty, replace_regexp, extract_regexp, substring, pad_left,
+// pad_right, format_number and one_of, taking the same arguments.
+func NewRegistry() *Registry {
+	r := &Registry{
+		sources:    make(map[string]SourceFactory),
+		targets:    make(map[string]TargetFactory),
+		transforms: make(map[string]TransformFactory),
+	}
+	r.RegisterSource("var", func(args Args) (mapjitsu.Source, error) {
+		name, err := args.String("name")
+		return mapjitsu.Var(name), err
+	})
+	r.RegisterTarget("var", func(args Args) (mapjitsu.Target, error) {
+		name, err := args.String("name")
+		return mapjitsu.Var(name), err
+	})
+	r.RegisterSource("const", func(args Args) (mapjitsu.Source, error) {
+		v, ok := args["value"]
+		if !ok {
+			return nil, fmt.Errorf("missing value")
+		}
+		return mapjitsu.SourceFunc(func() (interface{}, error) { return v, nil }), nil
+	})
+	registerTransforms(r)
+	return r
+}
+
+func (r *Registry) RegisterSource(name string, f SourceFactory) {
+	r.sources[name] = f
+}
+
+func (r *Registry) RegisterTarget(name string, f TargetFactory) {
+	r.targets[name] = f
+}
+
+func (r *Registry) RegisterTransform(name string, f TransformFactory) {
+	r.transforms[name] = f
+}
+
+// LoadYAML parses a YAML specification and returns the Definition it describes.
+func (r *Registry) LoadYAML(data []byte) (mapjitsu.Definition, error) {
+	s, err := ParseYAML(data)
+	if err != nil {
+		return mapjitsu.Definition{}, err
+	}
+	return r.Definition(s)
+}
+
+// LoadJSON parses a JSON specification and returns the Definition it describes.
+func (r *Registry) LoadJSON(data []byte) (mapjitsu.Definition, error) {
+	s, err := ParseJSON(data)
+	if err != nil {
+		return mapjitsu.Definition{}, err
+	}
+	return r.Definition(s)
+}
+
+// Definition returns the Definition described by s. Every unresolved
+// adapter or transform is reported, as mapjitsu.Errors of *Error.
+func (r *Registry) Definition(s *Spec) (mapjitsu.Definition, error) {
+	var d mapjitsu.Definition
+	var errs mapjitsu.Errors
+	for _, ms := range s.Mappings {
+		m := mapjitsu.Mapping{Name: ms.Name, OnError: errorPolicies[ms.OnError], Default: ms.Default, Optional: ms.Optional}
+
+		if f, ok := r.sources[ms.Source.Adapter]; !ok {
+			errs = append(errs, errorf(ms.Source.Line, "unknown source adapter %s", ms.Source.Adapter))
+		} else if source, err := f(ms.Source.Args); err != nil {
+			errs = append(errs, errorf(ms.Source.Line, "invalid %s source %v", ms.Source.Adapter, err))
+		} else {
+			m.Source = source
+		}
+
+		for _, ts := range ms.Transforms {
+			transform, err := r.transform(ts)
+			if err != nil {
+				errs = append(errs, err)
+			}
+			var inverse func(interface{}) (interface{}, error)
+			if ts.Inverse != nil {
+				inverse, err = r.transform(*ts.Inverse)
+				if err != nil {
+					errs = append(errs, err)
+				}
+			}
+			m.Transform = append(m.Transform, transform)
+			m.Inverse = append(m.Inverse, inverse)
+		}
+
+		if f, ok := r.targets[ms.Target.Adapter]; !ok {
+			errs = append(errs, errorf(ms.Target.Line, "unknown target adapter %s", ms.Target.Adapter))
+		} else if target, err := f(ms.Target.Args); err != nil {
+			errs = append(errs, errorf(ms.Target.Line, "invalid %s target %v", ms.Target.Adapter, err))
+		} else {
+			m.Target = target
+		}
+
+		d.Mappings = append(d.Mappings, m)
+	}
+	if len(errs) > 0 {
+		return mapjitsu.Definition{}, errs
+	}
+	return d, nil
+}
+
+func (r *Registry) transform(ts Transform) (func(interface{}) (interface{}, error), error) {
+	f, ok := r.transforms[ts.Name]
+	if !ok {
+		return nil, errorf(ts.Line, "unknown transform %s", ts.Name)
+	}
+	transform, err := f(ts.Args)
+	if err != nil {
+		return nil, errorf(ts.Line, "invalid %s transform %v", ts.Name, err)
+	}
+	return transform, nil
+}
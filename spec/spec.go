@@ -0,0 +1,297 @@
+// Package spec loads mapjitsu Definitions from JSON or YAML specifications.
+//
+// A specification lists mappings, each naming a source adapter, an optional
+// series of transforms and a target adapter e.g.
+//
+//	mappings:
+//	  - name: Customer FirstName
+//	    source: {adapter: input, path: user.first_name}
+//	    transforms:
+//	      - trim
+//	      - {name: default, args: [""]}
+//	      - {name: upper, inverse: lower}
+//	    target: {adapter: output, path: Customer.FirstName}
+//	  - source: {adapter: input, path: user.title}
+//	    on_error: default
+//	    default: ""
+//	    target: {adapter: output, path: Customer.Title}
+//	  - source: {adapter: input, path: user.nickname}
+//	    optional: true
+//	    target: {adapter: output, path: Customer.Nickname}
+//
+// Adapters and transforms are resolved by name through a Registry.
+package spec
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Spec is a parsed specification.
+type Spec struct {
+	Mappings []Mapping
+}
+
+// Mapping describes a mapjitsu.Mapping.
+type Mapping struct {
+	Line       int
+	Name       string
+	Source     Adapter
+	Transforms []Transform
+	Target     Adapter
+	OnError    string // fail, skip, default or collect
+	Default    interface{}
+	Optional   bool // skip the mapping if the source value is not found
+}
+
+// Adapter describes a Source or Target by the name of its adapter
+// together with any adapter specific arguments e.g. a path or column.
+type Adapter struct {
+	Line    int
+	Adapter string
+	Args    Args
+}
+
+// Transform describes a Pipeline function by name with optional arguments
+// and an optional Inverse, see mapjitsu.Definition.Reverse.
+type Transform struct {
+	Line    int
+	Name    string
+	Args    []interface{}
+	Inverse *Transform
+}
+
+// Error is a specification error at a given line, or 0 if the line is
+// not known.
+type Error struct {
+	Line int
+	Err  error
+}
+
+func (e *Error) Error() string {
+	if e.Line == 0 {
+		return e.Err.Error()
+	}
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+func errorf(line int, format string, a ...interface{}) *Error {
+	return &Error{Line: line, Err: fmt.Errorf(format, a...)}
+}
+
+// ParseYAML parses a YAML specification.
+func ParseYAML(data []byte) (*Spec, error) {
+	var doc yaml.Node
+	err := yaml.Unmarshal(data, &doc)
+	if err != nil {
+		return nil, yamlError(err)
+	}
+	if len(doc.Content) == 0 {
+		return nil, errorf(1, "empty specification")
+	}
+	return parse(doc.Content[0])
+}
+
+// ParseJSON parses a JSON specification.
+func ParseJSON(data []byte) (*Spec, error) {
+	var v interface{}
+	err := json.Unmarshal(data, &v)
+	if err != nil {
+		if syntaxError, ok := err.(*json.SyntaxError); ok {
+			return nil, &Error{Line: lineAt(data, syntaxError.Offset), Err: err}
+		}
+		return nil, fmt.Errorf("failed to parse specification %v", err)
+	}
+	// JSON is also valid YAML, which gives us line numbers for validation
+	return ParseYAML(data)
+}
+
+var yamlLine = regexp.MustCompile(`^yaml: line (\d+): (.*)$`)
+
+// yamlError returns err from yaml.Unmarshal as an *Error, taking the line
+// from its message e.g. "yaml: line 3: did not find expected key"
+func yamlError(err error) *Error {
+	match := yamlLine.FindStringSubmatch(err.Error())
+	if match == nil {
+		return &Error{Err: fmt.Errorf("failed to parse specification %v", err)}
+	}
+	line, _ := strconv.Atoi(match[1])
+	return &Error{Line: line, Err: errors.New(match[2])}
+}
+
+func lineAt(data []byte, offset int64) int {
+	if offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+	return bytes.Count(data[:offset], []byte("\n")) + 1
+}
+
+func parse(n *yaml.Node) (*Spec, error) {
+	if n.Kind != yaml.MappingNode {
+		return nil, errorf(n.Line, "expected an object containing mappings")
+	}
+	var spec Spec
+	var found bool
+	for i := 0; i < len(n.Content); i += 2 {
+		key, value := n.Content[i], n.Content[i+1]
+		if key.Value != "mappings" {
+			return nil, errorf(key.Line, "unknown field %s", key.Value)
+		}
+		found = true
+		if value.Kind != yaml.SequenceNode {
+			return nil, errorf(value.Line, "expected mappings to be a list")
+		}
+		for _, item := range value.Content {
+			m, err := parseMapping(item)
+			if err != nil {
+				return nil, err
+			}
+			spec.Mappings = append(spec.Mappings, m)
+		}
+	}
+	if !found {
+		return nil, errorf(n.Line, "missing mappings")
+	}
+	return &spec, nil
+}
+
+func parseMapping(n *yaml.Node) (Mapping, error) {
+	m := Mapping{Line: n.Line}
+	if n.Kind != yaml.MappingNode {
+		return m, errorf(n.Line, "expected a mapping object")
+	}
+	var err error
+	var hasSource, hasTarget bool
+	for i := 0; i < len(n.Content); i += 2 {
+		key, value := n.Content[i], n.Content[i+1]
+		switch key.Value {
+		case "name":
+			if value.Kind != yaml.ScalarNode {
+				return m, errorf(value.Line, "expected name to be a string")
+			}
+			m.Name = value.Value
+		case "source":
+			hasSource = true
+			m.Source, err = parseAdapter(value)
+		case "target":
+			hasTarget = true
+			m.Target, err = parseAdapter(value)
+		case "on_error":
+			switch value.Value {
+			case "fail", "skip", "default", "collect":
+				m.OnError = value.Value
+			default:
+				return m, errorf(value.Line, "invalid on_error %s, expected fail, skip, default or collect", value.Value)
+			}
+		case "default":
+			err = value.Decode(&m.Default)
+			if err != nil {
+				return m, errorf(value.Line, "invalid default %v", err)
+			}
+		case "optional":
+			err = value.Decode(&m.Optional)
+			if err != nil {
+				return m, errorf(value.Line, "expected optional to be true or false")
+			}
+		case "transforms":
+			if value.Kind != yaml.SequenceNode {
+				return m, errorf(value.Line, "expected transforms to be a list")
+			}
+			for _, item := range value.Content {
+				var t Transform
+				t, err = parseTransform(item)
+				if err != nil {
+					break
+				}
+				m.Transforms = append(m.Transforms, t)
+			}
+		default:
+			return m, errorf(key.Line, "unknown field %s", key.Value)
+		}
+		if err != nil {
+			return m, err
+		}
+	}
+	if !hasSource {
+		return m, errorf(n.Line, "mapping is missing a source")
+	}
+	if !hasTarget {
+		return m, errorf(n.Line, "mapping is missing a target")
+	}
+	return m, nil
+}
+
+func parseAdapter(n *yaml.Node) (Adapter, error) {
+	a := Adapter{Line: n.Line, Args: make(Args)}
+	if n.Kind != yaml.MappingNode {
+		return a, errorf(n.Line, "expected an adapter object")
+	}
+	for i := 0; i < len(n.Content); i += 2 {
+		key, value := n.Content[i], n.Content[i+1]
+		if key.Value == "adapter" {
+			if value.Kind != yaml.ScalarNode || value.Value == "" {
+				return a, errorf(value.Line, "expected adapter to be a name")
+			}
+			a.Adapter = value.Value
+			continue
+		}
+		var v interface{}
+		err := value.Decode(&v)
+		if err != nil {
+			return a, errorf(value.Line, "invalid %s %v", key.Value, err)
+		}
+		a.Args[key.Value] = v
+	}
+	if a.Adapter == "" {
+		return a, errorf(n.Line, "missing adapter")
+	}
+	return a, nil
+}
+
+func parseTransform(n *yaml.Node) (Transform, error) {
+	t := Transform{Line: n.Line}
+	switch n.Kind {
+	case yaml.ScalarNode:
+		t.Name = n.Value
+		return t, nil
+	case yaml.MappingNode:
+		for i := 0; i < len(n.Content); i += 2 {
+			key, value := n.Content[i], n.Content[i+1]
+			switch key.Value {
+			case "name":
+				t.Name = value.Value
+			case "args":
+				if value.Kind != yaml.SequenceNode {
+					return t, errorf(value.Line, "expected args to be a list")
+				}
+				err := value.Decode(&t.Args)
+				if err != nil {
+					return t, errorf(value.Line, "invalid args %v", err)
+				}
+			case "inverse":
+				inverse, err := parseTransform(value)
+				if err != nil {
+					return t, err
+				}
+				t.Inverse = &inverse
+			default:
+				return t, errorf(key.Line, "unknown field %s", key.Value)
+			}
+		}
+		if t.Name == "" {
+			return t, errorf(n.Line, "missing transform name")
+		}
+		return t, nil
+	}
+	return t, errorf(n.Line, "expected a transform name or object")
+}
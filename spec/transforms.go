@@ -0,0 +1,170 @@
+package spec
+
+import (
+	"fmt"
+	"regexp"
+	"unicode/utf8"
+
+	"github.com/8legd/mapjitsu/transforms"
+)
+
+// registerTransforms registers the transforms package under the names
+// used in specifications.
+func registerTransforms(r *Registry) {
+	noArgs := func(f func() func(interface{}) (interface{}, error)) TransformFactory {
+		return func(args []interface{}) (func(interface{}) (interface{}, error), error) {
+			if err := argCount(args, 0); err != nil {
+				return nil, err
+			}
+			return f(), nil
+		}
+	}
+	r.RegisterTransform("string", noArgs(transforms.ToString))
+	r.RegisterTransform("int", noArgs(transforms.ToInt))
+	r.RegisterTransform("float", noArgs(transforms.ToFloat))
+	r.RegisterTransform("bool", noArgs(transforms.ToBool))
+	r.RegisterTransform("upper", noArgs(transforms.ToUpper))
+	r.RegisterTransform("lower", noArgs(transforms.ToLower))
+
+	// trim removes white space or, given a cutset, the characters in cutset
+	r.RegisterTransform("trim", func(args []interface{}) (func(interface{}) (interface{}, error), error) {
+		if len(args) == 0 {
+			return transforms.TrimSpace(), nil
+		}
+		if err := argCount(args, 1); err != nil {
+			return nil, err
+		}
+		cutset, err := argString(args, 0)
+		if err != nil {
+			return nil, err
+		}
+		return transforms.Trim(cutset), nil
+	})
+	r.RegisterTransform("default", func(args []interface{}) (func(interface{}) (interface{}, error), error) {
+		if err := argCount(args, 1); err != nil {
+			return nil, err
+		}
+		return transforms.Default(args[0]), nil
+	})
+	r.RegisterTransform("default_if_empty", func(args []interface{}) (func(interface{}) (interface{}, error), error) {
+		if err := argCount(args, 1); err != nil {
+			return nil, err
+		}
+		return transforms.DefaultIfEmpty(args[0]), nil
+	})
+	r.RegisterTransform("replace_regexp", func(args []interface{}) (func(interface{}) (interface{}, error), error) {
+		if err := argCount(args, 2); err != nil {
+			return nil, err
+		}
+		re, err := argRegexp(args, 0)
+		if err != nil {
+			return nil, err
+		}
+		repl, err := argString(args, 1)
+		if err != nil {
+			return nil, err
+		}
+		return transforms.ReplaceRegexp(re, repl), nil
+	})
+	r.RegisterTransform("extract_regexp", func(args []interface{}) (func(interface{}) (interface{}, error), error) {
+		if err := argCount(args, 2); err != nil {
+			return nil, err
+		}
+		re, err := argRegexp(args, 0)
+		if err != nil {
+			return nil, err
+		}
+		group, err := argInt(args, 1)
+		if err != nil {
+			return nil, err
+		}
+		return transforms.ExtractRegexp(re, group), nil
+	})
+	r.RegisterTransform("substring", func(args []interface{}) (func(interface{}) (interface{}, error), error) {
+		if err := argCount(args, 2); err != nil {
+			return nil, err
+		}
+		start, err := argInt(args, 0)
+		if err != nil {
+			return nil, err
+		}
+		length, err := argInt(args, 1)
+		if err != nil {
+			return nil, err
+		}
+		return transforms.Substring(start, length), nil
+	})
+	pad := func(f func(int, rune) func(interface{}) (interface{}, error)) TransformFactory {
+		return func(args []interface{}) (func(interface{}) (interface{}, error), error) {
+			if err := argCount(args, 2); err != nil {
+				return nil, err
+			}
+			width, err := argInt(args, 0)
+			if err != nil {
+				return nil, err
+			}
+			s, err := argString(args, 1)
+			if err != nil {
+				return nil, err
+			}
+			if utf8.RuneCountInString(s) != 1 {
+				return nil, fmt.Errorf("argument 2 must be a single character")
+			}
+			r, _ := utf8.DecodeRuneInString(s)
+			return f(width, r), nil
+		}
+	}
+	r.RegisterTransform("pad_left", pad(transforms.PadLeft))
+	r.RegisterTransform("pad_right", pad(transforms.PadRight))
+	r.RegisterTransform("format_number", func(args []interface{}) (func(interface{}) (interface{}, error), error) {
+		if err := argCount(args, 1); err != nil {
+			return nil, err
+		}
+		decimals, err := argInt(args, 0)
+		if err != nil {
+			return nil, err
+		}
+		return transforms.FormatNumber(decimals), nil
+	})
+	r.RegisterTransform("one_of", func(args []interface{}) (func(interface{}) (interface{}, error), error) {
+		if len(args) == 0 {
+			return nil, fmt.Errorf("expected at least 1 argument")
+		}
+		return transforms.OneOf(args...), nil
+	})
+}
+
+func argCount(args []interface{}, n int) error {
+	if len(args) != n {
+		return fmt.Errorf("expected %d arguments, got %d", n, len(args))
+	}
+	return nil
+}
+
+func argString(args []interface{}, i int) (string, error) {
+	s, ok := args[i].(string)
+	if !ok {
+		return "", fmt.Errorf("argument %d has invalid type %T, expected string", i+1, args[i])
+	}
+	return s, nil
+}
+
+func argInt(args []interface{}, i int) (int, error) {
+	n, ok := args[i].(int)
+	if !ok {
+		return 0, fmt.Errorf("argument %d has invalid type %T, expected integer", i+1, args[i])
+	}
+	return n, nil
+}
+
+func argRegexp(args []interface{}, i int) (*regexp.Regexp, error) {
+	s, err := argString(args, i)
+	if err != nil {
+		return nil, err
+	}
+	re, err := regexp.Compile(s)
+	if err != nil {
+		return nil, fmt.Errorf("argument %d is not a valid regular expression %v", i+1, err)
+	}
+	return re, nil
+}
@@ -0,0 +1,93 @@
+package mapjitsu
+
+import (
+	"context"
+	"fmt"
+)
+
+// Checker is an optional interface implemented by Sources and Targets
+// which can check their configuration ahead of use. Compile calls Check
+// on every Source and Target implementing it.
+type Checker interface {
+	Check() error
+}
+
+// Template is a Definition compiled once, then executed many times
+// against input and output records bound at run time. The Mappings of a
+// Template use Sources and Targets which refer to fields of the bound
+// records through the context, such as csv/data Column or mxj/data Path,
+// rather than to a particular record.
+//
+// A Template is safe for concurrent use provided its Sources, Pipeline
+// functions and Targets are.
+type Template struct {
+	definition Definition
+	order      []int
+}
+
+// Compile checks d and returns it as a Template.
+func Compile(d Definition) (*Template, error) {
+	var errs Errors
+	for i, m := range d.Mappings {
+		if m.Source == nil {
+			errs = append(errs, m.error(i, StageSource, 0, nil, fmt.Errorf("missing source")))
+		} else if c, ok := m.Source.(Checker); ok {
+			if err := c.Check(); err != nil {
+				errs = append(errs, m.error(i, StageSource, 0, nil, err))
+			}
+		}
+		if m.Target == nil {
+			errs = append(errs, m.error(i, StageTarget, 0, nil, fmt.Errorf("missing target")))
+		} else if c, ok := m.Target.(Checker); ok {
+			if err := c.Check(); err != nil {
+				errs = append(errs, m.error(i, StageTarget, 0, nil, err))
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	order, err := d.order()
+	if err != nil {
+		return nil, err
+	}
+	return &Template{definition: d, order: order}, nil
+}
+
+// Execute applies the Template with input and output bound as the records
+// read and written by its Mappings.
+func (t *Template) Execute(input interface{}, output interface{}) error {
+	return t.ExecuteContext(context.Background(), input, output)
+}
+
+// ExecuteContext is Execute with a context, see Definition.ApplyContext.
+func (t *Template) ExecuteContext(ctx context.Context, input interface{}, output interface{}) error {
+	ctx = context.WithValue(ctx, recordsKey{}, records{input: input, output: output})
+	return t.definition.apply(ctx, t.order)
+}
+
+// Definition returns the Definition the Template was compiled from.
+func (t *Template) Definition() Definition {
+	return t.definition
+}
+
+type recordsKey struct{}
+
+type records struct {
+	input  interface{}
+	output interface{}
+}
+
+// Input returns the input record bound to ctx by Template.Execute, or
+// nil if there is none.
+func Input(ctx context.Context) interface{} {
+	r, _ := ctx.Value(recordsKey{}).(records)
+	return r.input
+}
+
+// Output returns the output record bound to ctx by Template.Execute, or
+// nil if there is none.
+func Output(ctx context.Context) interface{} {
+	r, _ := ctx.Value(recordsKey{}).(records)
+	return r.output
+}
@@ -0,0 +1,98 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/8legd/mapjitsu"
+	csvData "github.com/8legd/mapjitsu/csv/data"
+)
+
+// Example test mapping CSV records in parallel while preserving their order
+func TestRunnerParallel(t *testing.T) {
+
+	rows := 200
+	var input, expected strings.Builder
+	for row := 1; row <= rows; row++ {
+		fmt.Fprintf(&input, "%d,Test\n", row)
+		if row%50 != 0 {
+			fmt.Fprintf(&expected, "Test,%d\n", row)
+		}
+	}
+
+	// a transform taking a varying amount of time so records finish out of order
+	slow := func(v interface{}) (interface{}, error) {
+		time.Sleep(time.Duration(rand.Intn(2000)) * time.Microsecond)
+		return v, nil
+	}
+	// every 50th record fails
+	check := mapjitsu.Validator{
+		Rule: "check",
+		Check: func(v interface{}) error {
+			if n, _ := strconv.Atoi(v.(string)); n%50 == 0 {
+				return fmt.Errorf("bad record")
+			}
+			return nil
+		},
+	}
+
+	template, err := mapjitsu.Compile(mapjitsu.Definition{
+		Mappings: []mapjitsu.Mapping{
+			{
+				Source:    csvData.Column{Number: 1},
+				Transform: mapjitsu.Pipeline{slow},
+				Validate:  []mapjitsu.Validator{check},
+				Target:    csvData.Column{Number: 2},
+			},
+			{
+				Source: csvData.Column{Number: 2},
+				Target: csvData.Column{Number: 1},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to compile template %v", err)
+	}
+
+	var output strings.Builder
+	var skipped []int
+	runner := mapjitsu.Runner{
+		Reader:        csvData.NewReader(strings.NewReader(input.String())),
+		Writer:        csvData.NewWriter(&output),
+		Template:      template,
+		NewOutput:     func(interface{}) interface{} { return make([]string, 2) },
+		OnBadRecord:   mapjitsu.SkipBadRecord,
+		OnRecordError: func(err *mapjitsu.RecordError) { skipped = append(skipped, err.Record) },
+		Workers:       8,
+		InFlight:      16,
+	}
+	result, err := runner.Run(context.Background())
+	if err != nil {
+		t.Fatalf("failed to run %v", err)
+	}
+
+	if output.String() != expected.String() {
+		t.Errorf("resulting output does not preserve input order")
+	}
+	if fmt.Sprint(skipped) != "[50 100 150 200]" {
+		t.Errorf("expected records 50, 100, 150 and 200 to be skipped, got %v", skipped)
+	}
+	if result != (mapjitsu.RunResult{Read: rows, Written: rows - 4, Skipped: 4}) {
+		t.Errorf("unexpected result %+v", result)
+	}
+
+	// with FailOnBadRecord the first bad record in input order is reported
+	runner.Reader = csvData.NewReader(strings.NewReader(input.String()))
+	runner.Writer = csvData.NewWriter(&strings.Builder{})
+	runner.OnBadRecord = mapjitsu.FailOnBadRecord
+	_, err = runner.Run(context.Background())
+	if err == nil || !strings.HasPrefix(err.Error(), "record 50:") {
+		t.Errorf("expected an error at record 50, got %v", err)
+	}
+
+}
@@ -0,0 +1,200 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/8legd/mapjitsu"
+	csvData "github.com/8legd/mapjitsu/csv/data"
+	mxjData "github.com/8legd/mapjitsu/mxj/data"
+	"github.com/8legd/mapjitsu/transforms"
+	"github.com/8legd/mapjitsu/validators"
+	"github.com/clbanning/mxj"
+)
+
+// Example test streaming CSV records through a Template
+func TestRunner(t *testing.T) {
+
+	inputCSV := `first_name,last_name,dob
+Tim,Test,
+Tina,Test,01/01/2000
+Tom,Test,not a date
+Toby,Test,02/02/2002`
+
+	outputHeader := []string{"Customer DOB", "Customer FirstName", "Customer LastName"}
+
+	template, err := mapjitsu.Compile(mapjitsu.Definition{
+		Mappings: []mapjitsu.Mapping{
+			{
+				Source: csvData.Column{Number: 1},
+				Target: csvData.Column{Header: outputHeader, Name: "Customer FirstName"},
+			},
+			{
+				Source: csvData.Column{Number: 2},
+				Target: csvData.Column{Header: outputHeader, Name: "Customer LastName"},
+			},
+			{
+				Source:   csvData.Column{Number: 3},
+				Validate: []mapjitsu.Validator{validators.Regexp(regexp.MustCompile(`^(\d{2}/\d{2}/\d{4})?$`))},
+				Target:   csvData.Column{Header: outputHeader, Name: "Customer DOB"},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to compile template %v", err)
+	}
+
+	run := func(policy mapjitsu.BadRecordPolicy) (mapjitsu.RunResult, string, string, error) {
+		reader := csvData.NewReader(strings.NewReader(inputCSV))
+		if _, err := reader.ReadHeader(); err != nil {
+			t.Fatalf("failed to read header %v", err)
+		}
+		var output, quarantine strings.Builder
+		writer := csvData.NewWriter(&output)
+		writer.Write(outputHeader)
+		runner := mapjitsu.Runner{
+			Reader:      reader,
+			Writer:      writer,
+			Template:    template,
+			NewOutput:   func(interface{}) interface{} { return make([]string, len(outputHeader)) },
+			OnBadRecord: policy,
+			Quarantine:  csvData.NewWriter(&quarantine),
+		}
+		result, err := runner.Run(context.Background())
+		return result, output.String(), quarantine.String(), err
+	}
+
+	// by default the run stops at the first bad record
+	_, _, _, err = run(mapjitsu.FailOnBadRecord)
+	var recordError *mapjitsu.RecordError
+	if !errors.As(err, &recordError) || recordError.Record != 3 {
+		t.Fatalf("expected an error at record 3, got %v", err)
+	}
+	t.Logf("%v", err)
+
+	result, output, _, err := run(mapjitsu.SkipBadRecord)
+	if err != nil {
+		t.Fatalf("failed to run %v", err)
+	}
+	expected := `Customer DOB,Customer FirstName,Customer LastName
+,Tim,Test
+01/01/2000,Tina,Test
+02/02/2002,Toby,Test
+`
+	if output != expected {
+		t.Errorf("resulting output \n%s does not match expected \n%s", output, expected)
+	}
+	if result != (mapjitsu.RunResult{Read: 4, Written: 3, Skipped: 1}) {
+		t.Errorf("unexpected result %+v", result)
+	}
+
+	result, _, quarantine, err := run(mapjitsu.QuarantineBadRecord)
+	if err != nil {
+		t.Fatalf("failed to run %v", err)
+	}
+	if quarantine != "Tom,Test,not a date\n" {
+		t.Errorf("resulting quarantine %q does not match expected", quarantine)
+	}
+	if result.Quarantined != 1 {
+		t.Errorf("unexpected result %+v", result)
+	}
+
+}
+
+// Example test quarantining CSV rows with the wrong number of fields
+func TestRunnerFieldCount(t *testing.T) {
+
+	inputCSV := `first_name,last_name
+Tim,Test
+Tina
+Tom,Test,extra
+Toby,Test`
+
+	outputHeader := []string{"Customer LastName"}
+	template, err := mapjitsu.Compile(mapjitsu.Definition{
+		Mappings: []mapjitsu.Mapping{
+			{
+				Source: csvData.Column{Number: 2},
+				Target: csvData.Column{Header: outputHeader, Name: "Customer LastName"},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to compile template %v", err)
+	}
+
+	for _, workers := range []int{0, 2} {
+		reader := csvData.NewReader(strings.NewReader(inputCSV))
+		if _, err := reader.ReadHeader(); err != nil {
+			t.Fatalf("failed to read header %v", err)
+		}
+		var output, quarantine strings.Builder
+		var recordErrors []int
+		runner := mapjitsu.Runner{
+			Reader:        reader,
+			Writer:        csvData.NewWriter(&output),
+			Template:      template,
+			NewOutput:     func(interface{}) interface{} { return make([]string, len(outputHeader)) },
+			OnBadRecord:   mapjitsu.QuarantineBadRecord,
+			Quarantine:    csvData.NewWriter(&quarantine),
+			OnRecordError: func(err *mapjitsu.RecordError) { recordErrors = append(recordErrors, err.Record) },
+			Workers:       workers,
+		}
+		result, err := runner.Run(context.Background())
+		if err != nil {
+			t.Fatalf("workers %d: failed to run %v", workers, err)
+		}
+		if output.String() != "Test\nTest\n" || quarantine.String() != "Tina\nTom,Test,extra\n" {
+			t.Errorf("workers %d: unexpected output %q and quarantine %q", workers, output.String(), quarantine.String())
+		}
+		if result != (mapjitsu.RunResult{Read: 4, Written: 2, Quarantined: 2}) || fmt.Sprint(recordErrors) != "[2 3]" {
+			t.Errorf("workers %d: unexpected result %+v, record errors %v", workers, result, recordErrors)
+		}
+	}
+
+}
+
+// Example test streaming JSON lines through a per record Definition
+func TestRunnerJSON(t *testing.T) {
+
+	input := `{"user": {"first_name": "tim"}}
+{"user": {"first_name": "tina"}}
+`
+	var output strings.Builder
+	runner := mapjitsu.Runner{
+		Reader: mxjData.NewJSONReader(strings.NewReader(input)),
+		Writer: mxjData.NewJSONWriter(&output),
+		NewOutput: func(interface{}) interface{} {
+			return mxj.Map{"Customer": map[string]interface{}{}}
+		},
+		Definition: func(input interface{}, output interface{}) mapjitsu.Definition {
+			return mapjitsu.Definition{
+				Mappings: []mapjitsu.Mapping{
+					{
+						Source:    mxjData.Source{Map: input.(mxj.Map), Path: "user.first_name"},
+						Transform: mapjitsu.Pipeline{transforms.ToUpper()},
+						Target:    mxjData.Target{Map: output.(mxj.Map), Path: "Customer.FirstName"},
+					},
+				},
+			}
+		},
+	}
+	result, err := runner.Run(context.Background())
+	if err != nil {
+		t.Fatalf("failed to run %v", err)
+	}
+	expected := `{"Customer":{"FirstName":"TIM"}}
+{"Customer":{"FirstName":"TINA"}}
+`
+	if output.String() != expected {
+		t.Errorf("resulting output \n%s does not match expected \n%s", output.String(), expected)
+	}
+	if result.Written != 2 {
+		t.Errorf("unexpected result %+v", result)
+	}
+
+}
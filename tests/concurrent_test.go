@@ -0,0 +1,57 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/8legd/mapjitsu"
+	mxjData "github.com/8legd/mapjitsu/mxj/data"
+	"github.com/clbanning/mxj"
+)
+
+// Example test applying independent slow lookups concurrently
+func TestApplyConcurrent(t *testing.T) {
+
+	output := mxj.Map{
+		"Customer": make(map[string]interface{}),
+	}
+
+	// each lookup takes a while to return
+	lookup := func(v string) mapjitsu.Source {
+		return mapjitsu.SourceFunc(func() (interface{}, error) {
+			time.Sleep(50 * time.Millisecond)
+			return v, nil
+		})
+	}
+
+	var mappings []mapjitsu.Mapping
+	for i := 0; i < 8; i++ {
+		mappings = append(mappings, mapjitsu.Mapping{
+			Source: lookup(fmt.Sprintf("value %d", i)),
+			Target: mxjData.Target{Map: output, Path: fmt.Sprintf("Customer.Field%d", i)},
+		})
+	}
+	definition := mapjitsu.Definition{Mappings: mappings}
+
+	start := time.Now()
+	err := definition.ApplyConcurrent(context.Background(), 4)
+	if err != nil {
+		t.Fatalf("failed to apply mappings %v", err)
+	}
+	elapsed := time.Since(start)
+	if elapsed >= 8*50*time.Millisecond {
+		t.Errorf("expected lookups to run concurrently, took %v", elapsed)
+	}
+
+	for i := 0; i < 8; i++ {
+		path := fmt.Sprintf("Customer.Field%d", i)
+		expected := fmt.Sprintf("value %d", i)
+		if actual := output.ValueOrEmptyForPathString(path); actual != expected {
+			t.Errorf("resulting value %q for %s does not match expected %q", actual, path, expected)
+		}
+	}
+	t.Logf("applied %d mappings in %v", len(mappings), elapsed)
+
+}
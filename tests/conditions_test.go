@@ -0,0 +1,91 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/8legd/mapjitsu"
+	mxjData "github.com/8legd/mapjitsu/mxj/data"
+	"github.com/8legd/mapjitsu/transforms"
+	"github.com/clbanning/mxj"
+)
+
+// Example test mapping individual and business accounts differently
+func TestConditions(t *testing.T) {
+
+	apply := func(json string) mxj.Map {
+		input, err := mxj.NewMapJson([]byte(json))
+		if err != nil {
+			t.Fatalf("failed to unmarshal input %v", err)
+		}
+		output := mxj.Map{
+			"Customer": make(map[string]interface{}),
+		}
+
+		accountType := mxjData.Source{Map: input, Path: "account.type"}
+
+		definition := mapjitsu.Definition{
+			Mappings: []mapjitsu.Mapping{
+				{
+					// only individuals have a date of birth
+					Source:     mxjData.Source{Map: input, Path: "account.dob"},
+					When:       mapjitsu.Equals("individual"),
+					WhenSource: accountType,
+					Target:     mxjData.Target{Map: output, Path: "Customer.DOB"},
+				},
+				{
+					// the display name is chosen by account type
+					Source: mapjitsu.Switch{
+						On: accountType,
+						Cases: []mapjitsu.Case{
+							{
+								When:   mapjitsu.Equals("individual"),
+								Source: mxjData.Source{Map: input, Path: "account.name"},
+							},
+							{
+								When:      mapjitsu.Equals("business"),
+								Source:    mxjData.Source{Map: input, Path: "account.trading_name"},
+								Transform: mapjitsu.Pipeline{transforms.ToUpper()},
+							},
+						},
+					},
+					Target: mxjData.Target{Map: output, Path: "Customer.DisplayName"},
+				},
+				{
+					// the predicate can also test the source value itself
+					Source: mxjData.Source{Map: input, Path: "account.abn"},
+					When:   mapjitsu.Not(mapjitsu.Equals("")),
+					Target: mxjData.Target{Map: output, Path: "Customer.ABN"},
+				},
+			},
+		}
+
+		err = definition.Apply()
+		if err != nil {
+			t.Fatalf("failed to apply mappings %v", err)
+		}
+		return output
+	}
+
+	assert := func(output mxj.Map, expected string) {
+		json, err := output.Json()
+		if err != nil {
+			t.Fatalf("failed to marshal output %v", err)
+		}
+		if string(json) != expected {
+			t.Errorf("resulting json string \n%s\n does not match expected \n%s\n", json, expected)
+			return
+		}
+		t.Logf("%s", json)
+	}
+
+	assert(apply(`{"account": {"type": "individual", "name": "Tim Test", "dob": "01/01/2000", "abn": ""}}`),
+		`{"Customer":{"DOB":"01/01/2000","DisplayName":"Tim Test"}}`)
+
+	assert(apply(`{"account": {"type": "business", "trading_name": "Test Pty Ltd", "abn": "51824753556"}}`),
+		`{"Customer":{"ABN":"51824753556","DisplayName":"TEST PTY LTD"}}`)
+
+	// with no matching case the mapping is skipped
+	assert(apply(`{"account": {"type": "trust", "abn": ""}}`),
+		`{"Customer":{}}`)
+
+}
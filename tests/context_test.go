@@ -0,0 +1,71 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/8legd/mapjitsu"
+)
+
+// Example test honouring a deadline through a context aware Source
+func TestApplyContext(t *testing.T) {
+
+	// a slow lookup which gives up when the context is done
+	slowLookup := mapjitsu.ContextSourceFunc(func(ctx context.Context) (interface{}, error) {
+		select {
+		case <-time.After(time.Second):
+			return "found", nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	})
+
+	var results []interface{}
+	collect := mapjitsu.ContextTargetFunc(func(ctx context.Context, v interface{}) error {
+		results = append(results, v)
+		return nil
+	})
+
+	definition := mapjitsu.Definition{
+		Mappings: []mapjitsu.Mapping{
+			{
+				Source: mapjitsu.SourceFunc(func() (interface{}, error) { return "fast", nil }),
+				Target: collect,
+			},
+			{
+				Source: slowLookup,
+				Target: collect,
+			},
+			{
+				Source: mapjitsu.SourceFunc(func() (interface{}, error) { return "never", nil }),
+				Target: collect,
+			},
+		},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	err := definition.ApplyContext(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected context.DeadlineExceeded, got %v", err)
+	}
+	if len(results) != 1 || results[0] != "fast" {
+		t.Errorf("expected only the first mapping to be applied, got %v", results)
+	}
+
+	// a cancelled context stops any further mappings being applied
+	results = nil
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	err = definition.ApplyContext(ctx)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if len(results) != 0 {
+		t.Errorf("expected no mappings to be applied, got %v", results)
+	}
+
+}
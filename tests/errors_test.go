@@ -0,0 +1,118 @@
+package tests
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/8legd/mapjitsu"
+	csvData "github.com/8legd/mapjitsu/csv/data"
+)
+
+// Example test collecting every mapping failure rather than stopping at the first
+func TestCollectErrors(t *testing.T) {
+
+	errMissing := errors.New("missing value")
+
+	inputRecord := []string{"Tim", "Test"}
+	outputRecord := []string{"", "", ""}
+
+	definition := mapjitsu.Definition{
+		Mappings: []mapjitsu.Mapping{
+			{
+				Source: csvData.Source{Record: inputRecord, ColumnNumber: 1},
+				Target: csvData.Target{Record: outputRecord, ColumnNumber: 1},
+			},
+			{
+				// column 3 does not exist in the input record
+				Source: csvData.Source{Record: inputRecord, ColumnNumber: 3},
+				Target: csvData.Target{Record: outputRecord, ColumnNumber: 2},
+			},
+			{
+				Source: mapjitsu.SourceFunc(func() (interface{}, error) {
+					return nil, errMissing
+				}),
+				Target: csvData.Target{Record: outputRecord, ColumnNumber: 2},
+			},
+			{
+				Source: csvData.Source{Record: inputRecord, ColumnNumber: 2},
+				Target: csvData.Target{Record: outputRecord, ColumnNumber: 3},
+			},
+		},
+	}
+
+	// by default Apply stops at the first failure
+	err := definition.Apply()
+	if err == nil {
+		t.Fatalf("expected an error applying mappings")
+	}
+	if outputRecord[2] != "" {
+		t.Errorf("expected mappings after the first failure not to be applied, got %q", outputRecord[2])
+	}
+
+	// with CollectErrors every mapping is applied and all failures returned
+	definition.CollectErrors = true
+	err = definition.Apply()
+	var errs mapjitsu.Errors
+	if !errors.As(err, &errs) {
+		t.Fatalf("expected mapjitsu.Errors, got %T %v", err, err)
+	}
+	if len(errs) != 2 {
+		t.Errorf("expected 2 errors, got %d %v", len(errs), errs)
+	}
+	if !errors.Is(err, errMissing) {
+		t.Errorf("expected errors.Is to match a collected error")
+	}
+	if outputRecord[0] != "Tim" || outputRecord[2] != "Test" {
+		t.Errorf("expected successful mappings to be applied, got %v", outputRecord)
+	}
+	t.Logf("%v", err)
+
+}
+
+// Example test locating a failure through the returned MappingError
+func TestMappingError(t *testing.T) {
+
+	errInvalid := errors.New("invalid value")
+
+	inputRecord := []string{"Tim", "Test"}
+	outputRecord := []string{"", ""}
+
+	definition := mapjitsu.Definition{
+		Mappings: []mapjitsu.Mapping{
+			{
+				Name:   "Customer FirstName",
+				Source: csvData.Source{Record: inputRecord, ColumnNumber: 1},
+				Target: csvData.Target{Record: outputRecord, ColumnNumber: 1},
+			},
+			{
+				Name:   "Customer LastName",
+				Source: csvData.Source{Record: inputRecord, ColumnNumber: 2},
+				Transform: mapjitsu.Pipeline{
+					func(v interface{}) (interface{}, error) { return v, nil },
+					func(v interface{}) (interface{}, error) { return nil, errInvalid },
+				},
+				Target: csvData.Target{Record: outputRecord, ColumnNumber: 2},
+			},
+		},
+	}
+
+	err := definition.Apply()
+	var mappingError *mapjitsu.MappingError
+	if !errors.As(err, &mappingError) {
+		t.Fatalf("expected *mapjitsu.MappingError, got %T %v", err, err)
+	}
+	if mappingError.Index != 1 || mappingError.Name != "Customer LastName" {
+		t.Errorf("expected failure at mapping 1 Customer LastName, got %d %s", mappingError.Index, mappingError.Name)
+	}
+	if mappingError.Stage != mapjitsu.StageTransform || mappingError.Step != 1 {
+		t.Errorf("expected failure at transform 1, got %s %d", mappingError.Stage, mappingError.Step)
+	}
+	if mappingError.Value != "Test" {
+		t.Errorf("expected failing value Test, got %v", mappingError.Value)
+	}
+	if !errors.Is(err, errInvalid) {
+		t.Errorf("expected errors.Is to match the wrapped cause")
+	}
+	t.Logf("%v", err)
+
+}
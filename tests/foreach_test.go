@@ -0,0 +1,194 @@
+package tests
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/clbanning/mxj"
+
+	"github.com/8legd/mapjitsu"
+	mxjData "github.com/8legd/mapjitsu/mxj/data"
+	"github.com/8legd/mapjitsu/transforms"
+)
+
+// Example test mapping the lines of each order to the items of an invoice
+func TestForEach(t *testing.T) {
+
+	input, err := mxj.NewMapJson([]byte(`{
+		"orders": [
+			{"id": "A1", "tags": ["new"], "lines": [{"sku": "x", "qty": "1"}, {"sku": "y", "qty": "2"}]},
+			{"id": "B2", "tags": [], "lines": [{"sku": "z", "qty": "3"}]}
+		]
+	}`))
+	if err != nil {
+		t.Fatalf("failed to unmarshal input %v", err)
+	}
+	output := mxj.Map{}
+
+	item, err := mapjitsu.Compile(mapjitsu.Definition{
+		Mappings: []mapjitsu.Mapping{
+			{
+				Source: mxjData.Path("sku"),
+				Target: mxjData.Path("SKU"),
+			},
+			{
+				Source:    mxjData.Path("qty"),
+				Transform: mapjitsu.Pipeline{transforms.ToInt()},
+				Target:    mxjData.Path("Quantity"),
+			},
+			{
+				// the position of the line within the order
+				Source: mapjitsu.Index(0),
+				Target: mxjData.Path("Line"),
+			},
+			{
+				// the position of the order
+				Source: mapjitsu.Index(1),
+				Target: mxjData.Path("Invoice"),
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to compile item template %v", err)
+	}
+
+	tag, err := mapjitsu.Compile(mapjitsu.Definition{
+		Mappings: []mapjitsu.Mapping{
+			{
+				// the tags are strings rather than objects
+				Source:    mapjitsu.Element{},
+				Transform: mapjitsu.Pipeline{transforms.ToUpper()},
+				Target:    mxjData.Path("Name"),
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to compile tag template %v", err)
+	}
+
+	invoice, err := mapjitsu.Compile(mapjitsu.Definition{
+		Mappings: []mapjitsu.Mapping{
+			{
+				Source: mxjData.Path("id"),
+				Target: mxjData.Path("Order"),
+			},
+			{
+				Source: mapjitsu.ForEach{Source: mxjData.List{Path: "lines"}, Template: item},
+				Target: mxjData.Path("Items"),
+			},
+			{
+				Source: mapjitsu.ForEach{Source: mxjData.List{Path: "tags"}, Template: tag},
+				Target: mxjData.Path("Tags"),
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to compile invoice template %v", err)
+	}
+
+	definition := mapjitsu.Definition{
+		Mappings: []mapjitsu.Mapping{
+			{
+				Source: mapjitsu.ForEach{Source: mxjData.List{Map: input, Path: "orders"}, Template: invoice},
+				Target: mxjData.Target{Map: output, Path: "Invoices"},
+			},
+		},
+	}
+
+	err = definition.Apply()
+	if err != nil {
+		t.Fatalf("failed to apply definition %v", err)
+	}
+
+	json, err := output.Json()
+	if err != nil {
+		t.Fatalf("failed to marshal output %v", err)
+	}
+	expected := `{"Invoices":[` +
+		`{"Items":[{"Invoice":0,"Line":0,"Quantity":1,"SKU":"x"},{"Invoice":0,"Line":1,"Quantity":2,"SKU":"y"}],"Order":"A1","Tags":[{"Name":"NEW"}]},` +
+		`{"Items":[{"Invoice":1,"Line":0,"Quantity":3,"SKU":"z"}],"Order":"B2","Tags":[]}]}`
+	if string(json) != expected {
+		t.Errorf("resulting json string \n%s\n does not match expected \n%s\n", json, expected)
+	}
+}
+
+// Example test reporting the element which failed to map
+func TestForEachErrors(t *testing.T) {
+
+	input := mxj.Map{"lines": []interface{}{
+		map[string]interface{}{"sku": "x"},
+		map[string]interface{}{"code": "y"},
+	}}
+	output := mxj.Map{}
+
+	item, err := mapjitsu.Compile(mapjitsu.Definition{
+		Mappings: []mapjitsu.Mapping{
+			{
+				Source: mxjData.Path("sku"),
+				Target: mxjData.Path("SKU"),
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to compile item template %v", err)
+	}
+
+	definition := mapjitsu.Definition{
+		Mappings: []mapjitsu.Mapping{
+			{
+				// an optional list must still map every element
+				Source:   mapjitsu.ForEach{Source: mxjData.List{Map: input, Path: "lines"}, Template: item},
+				Optional: true,
+				Target:   mxjData.Target{Map: output, Path: "Items"},
+			},
+		},
+	}
+
+	err = definition.Apply()
+	var elementError *mapjitsu.ElementError
+	if !errors.As(err, &elementError) || elementError.Index != 1 {
+		t.Fatalf("expected element 1 to fail, got %v", err)
+	}
+	expected := "mapping 0 failed at source: element 1: mapping 0 failed at source: failed to return sku Path does not exist"
+	if err.Error() != expected {
+		t.Errorf("resulting error does not match expected error \n\n%s\n\n%s", err, expected)
+	}
+
+	_, err = mapjitsu.Compile(mapjitsu.Definition{
+		Mappings: []mapjitsu.Mapping{
+			{
+				Source: mapjitsu.ForEach{Source: mxjData.List{Path: "lines"}},
+				Target: mxjData.Path("Items"),
+			},
+		},
+	})
+	if err == nil {
+		t.Errorf("expected a ForEach without a template to fail to compile")
+	}
+}
+
+// Example test reading a whole list, where a Source reads its first element
+func TestList(t *testing.T) {
+
+	input, err := mxj.NewMapJson([]byte(`{"a": {"tags": ["x", "y"], "name": "z"}}`))
+	if err != nil {
+		t.Fatalf("failed to unmarshal input %v", err)
+	}
+
+	assert := func(source mapjitsu.Source, expected string) {
+		v, err := source.Value()
+		if err != nil {
+			t.Errorf("failed to read %v %v", source, err)
+			return
+		}
+		result := fmt.Sprintf("%v", v)
+		if result != expected {
+			t.Errorf("resulting %v value %s does not match expected value %s", source, result, expected)
+		}
+	}
+
+	assert(mxjData.Source{Map: input, Path: "a.tags"}, "x")
+	assert(mxjData.List{Map: input, Path: "a.tags"}, "[x y]")
+	assert(mxjData.List{Map: input, Path: "a.name"}, "[z]")
+}
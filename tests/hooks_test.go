@@ -0,0 +1,142 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/8legd/mapjitsu"
+	csvData "github.com/8legd/mapjitsu/csv/data"
+	"github.com/8legd/mapjitsu/transforms"
+)
+
+// recordingHooks logs each hook called
+type recordingHooks struct {
+	mapjitsu.NopHooks
+	log []string
+}
+
+func (h *recordingHooks) AfterSource(e mapjitsu.HookEvent) {
+	h.log = append(h.log, fmt.Sprintf("%d source %v", e.Index, e.Value))
+}
+
+func (h *recordingHooks) AfterStep(e mapjitsu.HookEvent) {
+	h.log = append(h.log, fmt.Sprintf("%d step %d %v", e.Index, e.Step, e.Value))
+}
+
+func (h *recordingHooks) AfterTarget(e mapjitsu.HookEvent) {
+	h.log = append(h.log, fmt.Sprintf("%d target %v", e.Index, e.Value))
+}
+
+func (h *recordingHooks) OnError(e mapjitsu.HookEvent, err error) {
+	h.log = append(h.log, fmt.Sprintf("%d error at %s", e.Index, e.Stage))
+}
+
+// Example test observing mapping execution through Hooks
+func TestHooks(t *testing.T) {
+
+	inputRecord := []string{" Tim ", "Test"}
+	outputRecord := []string{"", ""}
+
+	hooks := &recordingHooks{}
+	definition := mapjitsu.Definition{
+		Hooks:         hooks,
+		CollectErrors: true,
+		Mappings: []mapjitsu.Mapping{
+			{
+				Source:    csvData.Source{Record: inputRecord, ColumnNumber: 1},
+				Transform: mapjitsu.Pipeline{transforms.TrimSpace(), transforms.ToUpper()},
+				Target:    csvData.Target{Record: outputRecord, ColumnNumber: 1},
+			},
+			{
+				Source:    csvData.Source{Record: inputRecord, ColumnNumber: 2},
+				Transform: mapjitsu.Pipeline{transforms.ToInt()},
+				Target:    csvData.Target{Record: outputRecord, ColumnNumber: 2},
+			},
+		},
+	}
+
+	// hooks can also be passed for a single call through the context
+	perCall := &recordingHooks{}
+	err := definition.ApplyContext(mapjitsu.WithHooks(context.Background(), perCall))
+	var mappingError *mapjitsu.MappingError
+	if !errors.As(err, &mappingError) {
+		t.Fatalf("expected *mapjitsu.MappingError, got %T %v", err, err)
+	}
+
+	expected := strings.Join([]string{
+		"0 source  Tim ",
+		"0 step 0 Tim",
+		"0 step 1 TIM",
+		"0 target TIM",
+		"1 source Test",
+		"1 error at transform",
+	}, "\n")
+	for _, h := range []*recordingHooks{hooks, perCall} {
+		actual := strings.Join(h.log, "\n")
+		if actual != expected {
+			t.Errorf("resulting hooks \n%s\n do not match expected \n%s\n", actual, expected)
+		}
+	}
+
+}
+
+// Example test observing only the errors not recovered by an error policy
+func TestHooksErrorPolicy(t *testing.T) {
+
+	inputRecord := []string{"Test"}
+	outputRecord := []string{""}
+	errFailed := errors.New("target unavailable")
+
+	hooks := &recordingHooks{}
+	definition := mapjitsu.Definition{
+		Hooks:         hooks,
+		CollectErrors: true,
+		Mappings: []mapjitsu.Mapping{
+			{
+				Source:    csvData.Source{Record: inputRecord, ColumnNumber: 1},
+				Transform: mapjitsu.Pipeline{transforms.ToInt()},
+				OnError:   mapjitsu.SkipOnError,
+				Target:    csvData.Target{Record: outputRecord, ColumnNumber: 1},
+			},
+			{
+				Source:    csvData.Source{Record: inputRecord, ColumnNumber: 1},
+				Transform: mapjitsu.Pipeline{transforms.ToInt()},
+				OnError:   mapjitsu.DefaultOnError,
+				Default:   "0",
+				Target:    csvData.Target{Record: outputRecord, ColumnNumber: 1},
+			},
+			{
+				Source:  csvData.Source{Record: inputRecord, ColumnNumber: 1},
+				OnError: mapjitsu.SkipOnError,
+				Target:  mapjitsu.TargetFunc(func(interface{}) error { return errFailed }),
+			},
+			{
+				// DefaultOnError does not recover from errors setting the Target
+				Source:  csvData.Source{Record: inputRecord, ColumnNumber: 1},
+				OnError: mapjitsu.DefaultOnError,
+				Target:  mapjitsu.TargetFunc(func(interface{}) error { return errFailed }),
+			},
+		},
+	}
+
+	err := definition.Apply()
+	if !errors.Is(err, errFailed) {
+		t.Fatalf("expected %v, got %v", errFailed, err)
+	}
+
+	expected := strings.Join([]string{
+		"0 source Test",
+		"1 source Test",
+		"1 target 0",
+		"2 source Test",
+		"3 source Test",
+		"3 error at target",
+	}, "\n")
+	if actual := strings.Join(hooks.log, "\n"); actual != expected {
+		t.Errorf("resulting hooks \n%s\n do not match expected \n%s\n", actual, expected)
+	}
+
+}
@@ -0,0 +1,79 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/8legd/mapjitsu"
+	csvData "github.com/8legd/mapjitsu/csv/data"
+	mxjData "github.com/8legd/mapjitsu/mxj/data"
+	"github.com/8legd/mapjitsu/transforms"
+	"github.com/clbanning/mxj"
+)
+
+// Example test recording where each output field came from
+func TestLineage(t *testing.T) {
+
+	input, err := mxj.NewMapJson([]byte(`{
+		"user": {
+			"first_name": " tim ",
+			"code": "- TT -"
+		}
+	}`))
+	if err != nil {
+		t.Fatalf("failed to unmarshal input %v", err)
+	}
+
+	inputHeader := []string{"first_name", "last_name"}
+	inputRecord := []string{"Tim", "Test"}
+	outputRecord := []string{"", "", ""}
+
+	lineage := &mapjitsu.Lineage{}
+	definition := mapjitsu.Definition{
+		Hooks: lineage,
+		Mappings: []mapjitsu.Mapping{
+			{
+				Name:      "Customer FirstName",
+				Source:    mxjData.Source{Map: input, Path: "user.first_name"},
+				Transform: mapjitsu.Pipeline{transforms.TrimSpace(), transforms.ToUpper()},
+				Target:    csvData.Target{Record: outputRecord, ColumnNumber: 1},
+			},
+			{
+				Source: csvData.Source{Record: inputRecord, ColumnName: "last_name", Header: inputHeader},
+				Target: csvData.Target{Record: outputRecord, ColumnNumber: 2},
+			},
+			{
+				Source: mxjData.Source{Map: input, Path: "user.code"},
+				Transform: mapjitsu.Pipeline{
+					transforms.Trim("-"),
+					transforms.Trim(" "),
+					initial,
+					mapjitsu.Describe("suffix", func(v interface{}) (interface{}, error) { return v.(string) + "1", nil }),
+				},
+				Target: csvData.Target{Record: outputRecord, ColumnNumber: 3},
+			},
+		},
+	}
+
+	err = definition.Apply()
+	if err != nil {
+		t.Fatalf("failed to apply mappings %v", err)
+	}
+
+	json, err := lineage.JSON()
+	if err != nil {
+		t.Fatalf("failed to marshal lineage %v", err)
+	}
+
+	expected := `[{"index":0,"name":"Customer FirstName","source":"mxj path user.first_name","transforms":["transforms.TrimSpace()","transforms.ToUpper()"],"input":" tim ","output":"TIM","target":"csv column 1"},` +
+		`{"index":1,"source":"csv column last_name","input":"Test","output":"Test","target":"csv column 2"},` +
+		`{"index":2,"source":"mxj path user.code","transforms":["transforms.Trim(\"-\")","transforms.Trim(\" \")","tests.initial","suffix"],"input":"- TT -","output":"T1","target":"csv column 3"}]`
+	if string(json) != expected {
+		t.Errorf("resulting lineage \n%s\n does not match expected \n%s\n", json, expected)
+	}
+
+}
+
+// initial is a transform without a description, recorded by its name
+func initial(v interface{}) (interface{}, error) {
+	return v.(string)[:1], nil
+}
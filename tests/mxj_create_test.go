@@ -0,0 +1,144 @@
+package tests
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/clbanning/mxj"
+
+	"github.com/8legd/mapjitsu"
+	mxjData "github.com/8legd/mapjitsu/mxj/data"
+)
+
+// Example test writing to an MXJ Map without first creating its structure
+func TestMXJCreate(t *testing.T) {
+
+	input := mxj.Map{"user": map[string]interface{}{"first_name": "Tim", "last_name": "Test"}}
+	output := mxj.Map{}
+
+	definition := mapjitsu.Definition{
+		Mappings: []mapjitsu.Mapping{
+			{
+				Source: mxjData.Source{Map: input, Path: "user.first_name"},
+				Target: mxjData.Target{Map: output, Path: "Customer.Name.First", Create: true},
+			},
+			{
+				Source: mxjData.Source{Map: input, Path: "user.last_name"},
+				Target: mxjData.Target{Map: output, Path: "Customer.Name.Last", Create: true},
+			},
+			{
+				// missing list elements are created
+				Source: mxjData.Source{Map: input, Path: "user.first_name"},
+				Target: mxjData.Target{Map: output, Path: "Customer.Contacts[1].Name", Create: true},
+			},
+		},
+	}
+
+	err := definition.Apply()
+	if err != nil {
+		t.Fatalf("failed to apply definition %v", err)
+	}
+
+	json, err := output.Json()
+	if err != nil {
+		t.Fatalf("failed to marshal output %v", err)
+	}
+	expected := `{"Customer":{"Contacts":[null,{"Name":"Tim"}],"Name":{"First":"Tim","Last":"Test"}}}`
+	if string(json) != expected {
+		t.Errorf("resulting json string \n%s\n does not match expected \n%s\n", json, expected)
+	}
+
+	// an existing value which is not a map blocks the path
+	err = mxjData.Target{Map: output, Path: "Customer.Name.First.Initial", Create: true}.SetValue("T")
+	expectedError := "failed to set Customer.Name.First.Initial Customer.Name.First has type string, expected a map"
+	if err == nil || err.Error() != expectedError {
+		t.Errorf("resulting error does not match expected error \n\n%v\n\n%s", err, expectedError)
+	}
+	err = mxjData.Target{Map: output, Path: "Customer.Name[0]", Create: true}.SetValue("T")
+	expectedError = "failed to set Customer.Name[0] Customer.Name has type map[string]interface {}, expected a list"
+	if err == nil || err.Error() != expectedError {
+		t.Errorf("resulting error does not match expected error \n\n%v\n\n%s", err, expectedError)
+	}
+
+	// without Create the path must exist
+	err = mxjData.Target{Map: mxj.Map{}, Path: "Customer.FirstName"}.SetValue("Tim")
+	if !errors.Is(err, mxj.PathNotExistError) {
+		t.Errorf("expected mxj.PathNotExistError, got %v", err)
+	}
+}
+
+// Example test writing Template output records without first creating their structure
+func TestMXJCreatePath(t *testing.T) {
+
+	template, err := mapjitsu.Compile(mapjitsu.Definition{
+		Mappings: []mapjitsu.Mapping{
+			{
+				Source: mxjData.Path("user.first_name"),
+				Target: mxjData.Path("Customer.FirstName"),
+			},
+			{
+				Source: mxjData.Path("user.last_name"),
+				Target: mxjData.Path("Customer.Names[0]"),
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to compile template %v", err)
+	}
+
+	output := mxj.Map{}
+	err = template.Execute(mxj.Map{"user": map[string]interface{}{"first_name": "Tim", "last_name": "Test"}}, output)
+	if err != nil {
+		t.Fatalf("failed to execute template %v", err)
+	}
+	json, err := output.Json()
+	if err != nil {
+		t.Fatalf("failed to marshal output %v", err)
+	}
+	expected := `{"Customer":{"FirstName":"Tim","Names":["Test"]}}`
+	if string(json) != expected {
+		t.Errorf("resulting json string \n%s\n does not match expected \n%s\n", json, expected)
+	}
+}
+
+// Example test rolling back writes which created intermediate maps
+func TestMXJCreateRollback(t *testing.T) {
+
+	tests := []struct {
+		path   string
+		output mxj.Map
+	}{
+		{"Customer.Name.First", mxj.Map{}},
+		{"Items[1].Name", mxj.Map{}},
+		{"Items[0].Name", mxj.Map{"Items": []interface{}{map[string]interface{}{"SKU": "1"}}}},
+		{"Items[1].Name", mxj.Map{"Items": []interface{}{map[string]interface{}{"SKU": "1"}}}},
+		{"Items[0].Name", mxj.Map{"Items": []interface{}{nil}}},
+		{"Customer.Name", mxj.Map{"Customer": map[string]interface{}{"Name": "Tim"}}},
+	}
+
+	for _, test := range tests {
+		expected := fmt.Sprint(test.output)
+		definition := mapjitsu.Definition{
+			Transactional: true,
+			Mappings: []mapjitsu.Mapping{
+				{
+					Source: mapjitsu.SourceFunc(func() (interface{}, error) { return "Tom", nil }),
+					Target: mxjData.Target{Map: test.output, Path: test.path, Create: true},
+				},
+				{
+					Source: mapjitsu.SourceFunc(func() (interface{}, error) { return "Test", nil }),
+					Target: mapjitsu.TargetFunc(func(interface{}) error { return errors.New("unavailable") }),
+				},
+			},
+		}
+
+		err := definition.Apply()
+		if err == nil {
+			t.Fatalf("%s: expected the definition to fail", test.path)
+		}
+		if actual := fmt.Sprint(test.output); actual != expected {
+			t.Errorf("%s: expected the write to be rolled back to %s, got %s", test.path, expected, actual)
+		}
+	}
+}
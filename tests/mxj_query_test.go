@@ -0,0 +1,108 @@
+package tests
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/clbanning/mxj"
+
+	"github.com/8legd/mapjitsu"
+	mxjData "github.com/8legd/mapjitsu/mxj/data"
+)
+
+// Example test querying an MXJ Map for fields in varying or repeated positions
+func TestMXJQuery(t *testing.T) {
+
+	input, err := mxj.NewMapJson([]byte(`{
+		"customer": {"id": "C1"},
+		"accounts": {
+			"savings": {"id": "S1", "balance": 10},
+			"cheque": {"id": "Q1", "balance": 0}
+		},
+		"orders": [
+			{"id": "A1", "status": "open", "lines": [{"sku": "x"}, {"sku": "y"}]},
+			{"id": "B2", "status": "closed", "lines": [{"sku": "z"}]}
+		]
+	}`))
+	if err != nil {
+		t.Fatalf("failed to unmarshal input %v", err)
+	}
+
+	assert := func(query mxjData.Query, expected string) {
+		v, err := query.Value()
+		if err != nil {
+			t.Errorf("failed to query %s %v", query.Path, err)
+			return
+		}
+		result := fmt.Sprintf("%v", v)
+		if result != expected {
+			t.Errorf("resulting %s value %s does not match expected value %s", query.Path, result, expected)
+		}
+	}
+
+	assert(mxjData.Query{Map: input, Path: "orders.id"}, "[A1 B2]")
+	assert(mxjData.Query{Map: input, Path: "orders.lines.sku"}, "[x y z]")
+	assert(mxjData.Query{Map: input, Path: "orders.lines.sku", Select: mxjData.First}, "x")
+	assert(mxjData.Query{Map: input, Path: "orders.lines.sku", Select: mxjData.Last}, "z")
+	assert(mxjData.Query{Map: input, Path: "orders", Filter: []string{"status:open"}, Select: mxjData.First}, "map[id:A1 lines:[map[sku:x] map[sku:y]] status:open]")
+	assert(mxjData.Query{Map: input, Path: "accounts.*.balance", Where: mapjitsu.Not(mapjitsu.Equals(0.0))}, "[10]")
+	assert(mxjData.Query{Map: input, Path: "orders..sku"}, "[x y z]")
+
+	v, err := mxjData.Query{Map: input, Path: "..id"}.Value()
+	if err != nil {
+		t.Fatalf("failed to query ..id %v", err)
+	}
+	if len(v.([]interface{})) != 5 {
+		t.Errorf("expected 5 ids at any depth, got %v", v)
+	}
+
+	_, err = mxjData.Query{Map: input, Path: "orders.lines.qty"}.Value()
+	if !errors.Is(err, mapjitsu.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
+// Example test querying the input records bound to a Template
+func TestMXJQueryTemplate(t *testing.T) {
+
+	template, err := mapjitsu.Compile(mapjitsu.Definition{
+		Mappings: []mapjitsu.Mapping{
+			{
+				Source: mxjData.Query{Path: "..email", Select: mxjData.First},
+				Target: mxjData.Path("Customer.Email"),
+			},
+			{
+				Source:   mxjData.Query{Path: "..phone", Select: mxjData.First},
+				Optional: true,
+				Target:   mxjData.Path("Customer.Phone"),
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to compile template %v", err)
+	}
+
+	inputs := []mxj.Map{
+		{"email": "tim@test.com"},
+		{"contact": map[string]interface{}{"details": map[string]interface{}{"email": "tina@test.com"}}},
+	}
+	expected := []string{
+		`{"Customer":{"Email":"tim@test.com"}}`,
+		`{"Customer":{"Email":"tina@test.com"}}`,
+	}
+	for i, input := range inputs {
+		output := mxj.Map{}
+		err = template.Execute(input, output)
+		if err != nil {
+			t.Fatalf("failed to execute template %v", err)
+		}
+		json, err := output.Json()
+		if err != nil {
+			t.Fatalf("failed to marshal output %v", err)
+		}
+		if string(json) != expected[i] {
+			t.Errorf("resulting json string \n%s\n does not match expected \n%s\n", json, expected[i])
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package tests
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/clbanning/mxj"
+
+	"github.com/8legd/mapjitsu"
+	mxjData "github.com/8legd/mapjitsu/mxj/data"
+)
+
+// Example test merging enrichment data into an existing customer document
+func TestMXJStrategies(t *testing.T) {
+
+	enrichment := mxj.Map{
+		"name":    "Tina",
+		"address": map[string]interface{}{"city": "Sydney", "geo": map[string]interface{}{"lat": -33.9}},
+		"tags":    []interface{}{"vip", "newsletter"},
+	}
+	output := mxj.Map{
+		"Customer": map[string]interface{}{
+			"Name":    "Tim",
+			"Title":   nil,
+			"Address": map[string]interface{}{"Street": "1 Test St", "geo": map[string]interface{}{"lng": 151.2}},
+			"Tags":    []interface{}{"existing"},
+		},
+	}
+
+	definition := mapjitsu.Definition{
+		Mappings: []mapjitsu.Mapping{
+			{
+				// the existing name is kept
+				Source: mxjData.Source{Map: enrichment, Path: "name"},
+				Target: mxjData.Target{Map: output, Path: "Customer.Name", Strategy: mxjData.KeepExisting},
+			},
+			{
+				// a missing nickname is written
+				Source: mxjData.Source{Map: enrichment, Path: "name"},
+				Target: mxjData.Target{Map: output, Path: "Customer.Nickname", Strategy: mxjData.KeepExisting},
+			},
+			{
+				Source: mxjData.Source{Map: enrichment, Path: "address"},
+				Target: mxjData.Target{Map: output, Path: "Customer.Address", Strategy: mxjData.DeepMerge},
+			},
+			{
+				Source: mxjData.List{Map: enrichment, Path: "tags"},
+				Target: mxjData.Target{Map: output, Path: "Customer.Tags", Strategy: mxjData.Append},
+			},
+			{
+				Source: mxjData.Source{Map: enrichment, Path: "name"},
+				Target: mxjData.Target{Map: output, Path: "Customer.Names", Strategy: mxjData.Append},
+			},
+		},
+	}
+
+	err := definition.Apply()
+	if err != nil {
+		t.Fatalf("failed to apply definition %v", err)
+	}
+
+	json, err := output.Json()
+	if err != nil {
+		t.Fatalf("failed to marshal output %v", err)
+	}
+	expected := `{"Customer":{"Address":{"Street":"1 Test St","city":"Sydney","geo":{"lat":-33.9,"lng":151.2}},` +
+		`"Name":"Tim","Names":["Tina"],"Nickname":"Tina","Tags":["existing","vip","newsletter"],"Title":null}}`
+	if string(json) != expected {
+		t.Errorf("resulting json string \n%s\n does not match expected \n%s\n", json, expected)
+	}
+
+	// the source of merged maps is unchanged
+	geo := enrichment["address"].(map[string]interface{})["geo"].(map[string]interface{})
+	if len(geo) != 1 {
+		t.Errorf("expected the enrichment data to be unchanged, got %v", enrichment)
+	}
+
+	// a null value is present
+	err = mxjData.Target{Map: output, Path: "Customer.Title", Strategy: mxjData.ErrorIfPresent}.SetValue("Mx")
+	if !errors.Is(err, mxjData.ErrPresent) {
+		t.Errorf("expected ErrPresent, got %v", err)
+	}
+
+	err = mxjData.Target{Map: output, Path: "Customer.Name", Strategy: mxjData.Append}.SetValue("Tina")
+	expectedError := "failed to set Customer.Name existing value has type string, expected a list"
+	if err == nil || err.Error() != expectedError {
+		t.Errorf("resulting error does not match expected error \n\n%v\n\n%s", err, expectedError)
+	}
+}
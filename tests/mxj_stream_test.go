@@ -0,0 +1,178 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/8legd/mapjitsu"
+	mxjData "github.com/8legd/mapjitsu/mxj/data"
+	"github.com/8legd/mapjitsu/transforms"
+	"github.com/clbanning/mxj"
+)
+
+// Example test streaming the repeated elements of an XML export
+func TestXMLElementReader(t *testing.T) {
+
+	inputXML := `<?xml version="1.0"?>
+<ex:Export xmlns:ex="http://example.com/export">
+	<ex:Header><ex:Record id="0">not a record</ex:Record></ex:Header>
+	<ex:Record id="1"><Name>Tim</Name><Age>42</Age></ex:Record>
+	<ex:Record id="2"><Name>Tina</Name><Age>unknown</Age></ex:Record>
+	<ex:Record id="3"><Name>Tom</Name><Age>7</Age></ex:Record>
+</ex:Export>`
+
+	template, err := mapjitsu.Compile(mapjitsu.Definition{
+		Mappings: []mapjitsu.Mapping{
+			{
+				Source: mxjData.XMLPath("ex:Record.@id"),
+				Target: mxjData.Path("id"),
+			},
+			{
+				Source: mxjData.XMLPath("ex:Record.Name"),
+				Target: mxjData.Path("name"),
+			},
+			{
+				Source:    mxjData.XMLPath("ex:Record.Age"),
+				Transform: mapjitsu.Pipeline{transforms.ToInt()},
+				Target:    mxjData.Path("age"),
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to compile template %v", err)
+	}
+
+	run := func(policy mapjitsu.BadRecordPolicy) (string, error) {
+		var output strings.Builder
+		runner := mapjitsu.Runner{
+			Reader:      mxjData.NewXMLElementReader(strings.NewReader(inputXML), "ex:Export.ex:Record"),
+			Writer:      mxjData.NewJSONWriter(&output),
+			Template:    template,
+			NewOutput:   func(interface{}) interface{} { return mxj.Map{} },
+			OnBadRecord: policy,
+		}
+		_, err := runner.Run(context.Background())
+		return output.String(), err
+	}
+
+	// the error includes the position of the bad record
+	_, err = run(mapjitsu.FailOnBadRecord)
+	var recordError *mapjitsu.RecordError
+	if !errors.As(err, &recordError) || recordError.Record != 2 {
+		t.Fatalf("expected an error at record 2, got %v", err)
+	}
+	expected := int64(strings.Index(inputXML, `<ex:Record id="2">`))
+	if recordError.Offset != expected {
+		t.Errorf("resulting offset %d does not match expected offset %d", recordError.Offset, expected)
+	}
+	t.Logf("%v", err)
+
+	output, err := run(mapjitsu.SkipBadRecord)
+	if err != nil {
+		t.Fatalf("failed to run %v", err)
+	}
+	expectedOutput := `{"age":42,"id":"1","name":"Tim"}
+{"age":7,"id":"3","name":"Tom"}
+`
+	if output != expectedOutput {
+		t.Errorf("resulting output does not match expected output \n\n%s\n\n%s", output, expectedOutput)
+	}
+
+	// a truncated document is an error once the last complete element is read
+	reader := mxjData.NewXMLElementReader(strings.NewReader(inputXML[:strings.Index(inputXML, `<Name>Tom`)]), "ex:Export.ex:Record")
+	for i := 0; i < 2; i++ {
+		if _, err := reader.Read(); err != nil {
+			t.Fatalf("failed to read element %d %v", i+1, err)
+		}
+	}
+	_, err = reader.Read()
+	if !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("expected an unexpected EOF, got %v", err)
+	}
+}
+
+// Example test streaming the objects of a list in a JSON export
+func TestJSONElementReader(t *testing.T) {
+
+	inputJSON := `{
+	"export": {
+		"created": "2020-01-01",
+		"records": [
+			{"id": 1, "name": "Tim", "tags": [{"name": "new"}]},
+			{"id": 2, "name": "Tina"},
+			{"id": 3}
+		],
+		"count": 3
+	}
+}`
+
+	template, err := mapjitsu.Compile(mapjitsu.Definition{
+		Mappings: []mapjitsu.Mapping{
+			{
+				Source: mxjData.Path("id"),
+				Target: mxjData.Path("Id"),
+			},
+			{
+				Source:   mxjData.Path("name"),
+				Optional: true,
+				Target:   mxjData.Path("Name"),
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to compile template %v", err)
+	}
+
+	var output strings.Builder
+	runner := mapjitsu.Runner{
+		Reader:    mxjData.NewJSONElementReader(strings.NewReader(inputJSON), "export.records"),
+		Writer:    mxjData.NewJSONWriter(&output),
+		Template:  template,
+		NewOutput: func(interface{}) interface{} { return mxj.Map{} },
+	}
+	_, err = runner.Run(context.Background())
+	if err != nil {
+		t.Fatalf("failed to run %v", err)
+	}
+	expectedOutput := `{"Id":1,"Name":"Tim"}
+{"Id":2,"Name":"Tina"}
+{"Id":3}
+`
+	if output.String() != expectedOutput {
+		t.Errorf("resulting output does not match expected output \n\n%s\n\n%s", output.String(), expectedOutput)
+	}
+
+	// the error includes the position of the bad record
+	inputJSON = strings.Replace(inputJSON, `{"id": 3}`, `"3"`, 1)
+	runner.Reader = mxjData.NewJSONElementReader(strings.NewReader(inputJSON), "export.records")
+	_, err = runner.Run(context.Background())
+	var recordError *mapjitsu.RecordError
+	if !errors.As(err, &recordError) || recordError.Record != 3 {
+		t.Fatalf("expected an error at record 3, got %v", err)
+	}
+	expected := int64(strings.Index(inputJSON, `"3"`))
+	if recordError.Offset != expected {
+		t.Errorf("resulting offset %d does not match expected offset %d", recordError.Offset, expected)
+	}
+	t.Logf("%v", err)
+
+	// a list at the root of the document is read with an empty path
+	reader := mxjData.NewJSONElementReader(strings.NewReader(`[{"id": 1}, {"id": 2}]`), "")
+	var ids []interface{}
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("failed to read record %v", err)
+		}
+		ids = append(ids, record.(mxj.Map)["id"])
+	}
+	if len(ids) != 2 || ids[0] != 1.0 || ids[1] != 2.0 {
+		t.Errorf("resulting ids %v do not match expected ids [1 2]", ids)
+	}
+}
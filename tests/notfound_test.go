@@ -0,0 +1,169 @@
+package tests
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/clbanning/mxj"
+
+	"github.com/8legd/mapjitsu"
+	csvData "github.com/8legd/mapjitsu/csv/data"
+	mxjData "github.com/8legd/mapjitsu/mxj/data"
+	"github.com/8legd/mapjitsu/spec"
+)
+
+// Example test checking every builtin adapter reports a missing value as ErrNotFound
+func TestNotFound(t *testing.T) {
+
+	input := mxj.Map{"user": map[string]interface{}{"nickname": nil}}
+	header := []string{"first_name"}
+	record := []string{"Tim"}
+
+	sources := map[string]mapjitsu.Source{
+		"mxj source":      mxjData.Source{Map: input, Path: "user.title"},
+		"csv column name": csvData.Source{Header: header, Record: record, ColumnName: "title"},
+		"csv column":      csvData.Source{Record: record, ColumnNumber: 2},
+	}
+	for name, source := range sources {
+		_, err := source.Value()
+		if !errors.Is(err, mapjitsu.ErrNotFound) {
+			t.Errorf("expected %s to return ErrNotFound, got %v", name, err)
+		}
+	}
+
+	// the original cause is still available
+	_, err := mxjData.Source{Map: input, Path: "user.title"}.Value()
+	if !errors.Is(err, mxj.PathNotExistError) {
+		t.Errorf("expected mxj.PathNotExistError, got %v", err)
+	}
+
+	// a value which is present but null is not an error
+	v, err := mxjData.Source{Map: input, Path: "user.nickname"}.Value()
+	if err != nil || v != nil {
+		t.Errorf("expected a nil value, got %v %v", v, err)
+	}
+
+	// configuration errors are not reported as absent values
+	_, err = csvData.Source{Record: record}.Value()
+	if err == nil || errors.Is(err, mapjitsu.ErrNotFound) {
+		t.Errorf("expected a configuration error, got %v", err)
+	}
+
+	// a variable which was not set is absent
+	definition := mapjitsu.Definition{
+		Mappings: []mapjitsu.Mapping{
+			{
+				Source: mxjData.Source{Map: input, Path: "user.nickname"},
+				When:   mapjitsu.Equals("never"),
+				Target: mapjitsu.Var("title"),
+			},
+			{
+				Source: mapjitsu.Var("title"),
+				Target: mapjitsu.TargetFunc(func(interface{}) error { return nil }),
+			},
+		},
+	}
+	err = definition.Apply()
+	if !errors.Is(err, mapjitsu.ErrNotFound) {
+		t.Errorf("expected an unset variable to return ErrNotFound, got %v", err)
+	}
+}
+
+// Example test declaring optional fields
+func TestOptional(t *testing.T) {
+
+	input := mxj.Map{"user": map[string]interface{}{"first_name": "Tim"}}
+	output := mxj.Map{"Customer": map[string]interface{}{}}
+
+	definition := mapjitsu.Definition{
+		Mappings: []mapjitsu.Mapping{
+			{
+				Source: mxjData.Source{Map: input, Path: "user.first_name"},
+				Target: mxjData.Target{Map: output, Path: "Customer.FirstName"},
+			},
+			{
+				// the nickname is absent so the mapping is skipped
+				Source:   mxjData.Source{Map: input, Path: "user.nickname"},
+				Optional: true,
+				Target:   mxjData.Target{Map: output, Path: "Customer.Nickname"},
+			},
+			{
+				// the title is absent so the default is written
+				Source: mapjitsu.Optional{Source: mxjData.Source{Map: input, Path: "user.title"}, Default: "Mx"},
+				Target: mxjData.Target{Map: output, Path: "Customer.Title"},
+			},
+		},
+	}
+
+	err := definition.Apply()
+	if err != nil {
+		t.Fatalf("failed to apply definition %v", err)
+	}
+
+	expected := "map[Customer:map[FirstName:Tim Title:Mx]]"
+	result := fmt.Sprintf("%v", output)
+	if result != expected {
+		t.Errorf("resulting output does not match expected output \n\n%s\n\n%s", result, expected)
+	}
+
+	// other errors still fail an optional mapping
+	definition = mapjitsu.Definition{
+		Mappings: []mapjitsu.Mapping{
+			{
+				Source:   csvData.Source{Record: []string{"Tim"}},
+				Optional: true,
+				Target:   mxjData.Target{Map: output, Path: "Customer.FirstName"},
+			},
+		},
+	}
+	err = definition.Apply()
+	if err == nil {
+		t.Errorf("expected an optional mapping with an invalid source to fail")
+	}
+}
+
+// Example test declaring optional fields in a specification
+func TestOptionalSpec(t *testing.T) {
+
+	input := mxj.Map{"user": map[string]interface{}{"first_name": "Tim"}}
+	output := mxj.Map{"Customer": map[string]interface{}{}}
+
+	registry := spec.NewRegistry()
+	registry.RegisterSource("input", func(args spec.Args) (mapjitsu.Source, error) {
+		path, err := args.String("path")
+		if err != nil {
+			return nil, err
+		}
+		return mxjData.Source{Map: input, Path: path}, nil
+	})
+	registry.RegisterTarget("output", func(args spec.Args) (mapjitsu.Target, error) {
+		path, err := args.String("path")
+		if err != nil {
+			return nil, err
+		}
+		return mxjData.Target{Map: output, Path: path}, nil
+	})
+
+	definition, err := registry.LoadYAML([]byte(`
+mappings:
+  - source: {adapter: input, path: user.first_name}
+    target: {adapter: output, path: Customer.FirstName}
+  - source: {adapter: input, path: user.nickname}
+    optional: true
+    target: {adapter: output, path: Customer.Nickname}
+`))
+	if err != nil {
+		t.Fatalf("failed to load specification %v", err)
+	}
+	err = definition.Apply()
+	if err != nil {
+		t.Fatalf("failed to apply definition %v", err)
+	}
+
+	expected := "map[Customer:map[FirstName:Tim]]"
+	result := fmt.Sprintf("%v", output)
+	if result != expected {
+		t.Errorf("resulting output does not match expected output \n\n%s\n\n%s", result, expected)
+	}
+}
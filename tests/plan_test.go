@@ -0,0 +1,78 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/8legd/mapjitsu"
+	mxjData "github.com/8legd/mapjitsu/mxj/data"
+	"github.com/8legd/mapjitsu/transforms"
+	"github.com/clbanning/mxj"
+)
+
+// Example test previewing the writes a Definition would make
+func TestPlan(t *testing.T) {
+
+	input, err := mxj.NewMapJson([]byte(`{
+		"user": {
+			"first_name": "Tim",
+			"age": "42"
+		}
+	}`))
+	if err != nil {
+		t.Fatalf("failed to unmarshal input %v", err)
+	}
+
+	output := mxj.Map{
+		"Customer": map[string]interface{}{
+			"FirstName": "Timothy",
+		},
+	}
+
+	definition := mapjitsu.Definition{
+		Mappings: []mapjitsu.Mapping{
+			{
+				Source: mxjData.Source{Map: input, Path: "user.first_name"},
+				Target: mapjitsu.Var("first_name"),
+			},
+			{
+				Source: mapjitsu.Var("first_name"),
+				Target: mxjData.Target{Map: output, Path: "Customer.FirstName"},
+			},
+			{
+				Name:      "Customer Age",
+				Source:    mxjData.Source{Map: input, Path: "user.age"},
+				Transform: mapjitsu.Pipeline{transforms.ToInt()},
+				Target:    mxjData.Target{Map: output, Path: "Customer.Age"},
+			},
+		},
+	}
+
+	plan, err := definition.Plan()
+	if err != nil {
+		t.Fatalf("failed to plan mappings %v", err)
+	}
+
+	expected := []mapjitsu.PlannedWrite{
+		{Index: 1, Target: "mxj path Customer.FirstName", Value: "Tim", Type: "string"},
+		{Index: 2, Name: "Customer Age", Target: "mxj path Customer.Age", Value: 42, Type: "int"},
+	}
+	if len(plan) != len(expected) {
+		t.Fatalf("expected %d planned writes, got %v", len(expected), plan)
+	}
+	for i, write := range plan {
+		if write != expected[i] {
+			t.Errorf("resulting planned write %+v does not match expected %+v", write, expected[i])
+		}
+		t.Logf("%+v", write)
+	}
+
+	// the output is left untouched
+	json, err := output.Json()
+	if err != nil {
+		t.Fatalf("failed to marshal output %v", err)
+	}
+	if string(json) != `{"Customer":{"FirstName":"Timothy"}}` {
+		t.Errorf("expected output not to be modified, got %s", json)
+	}
+
+}
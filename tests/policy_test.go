@@ -0,0 +1,65 @@
+package tests
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/8legd/mapjitsu"
+	csvData "github.com/8legd/mapjitsu/csv/data"
+	"github.com/8legd/mapjitsu/transforms"
+)
+
+// Example test handling mapping failures with per mapping error policies
+func TestErrorPolicies(t *testing.T) {
+
+	inputHeader := []string{"first_name", "last_name", "age"}
+	inputRecord := []string{"Tim", "Test", "unknown"}
+	outputRecord := []string{"", "", "", ""}
+
+	definition := mapjitsu.Definition{
+		Mappings: []mapjitsu.Mapping{
+			{
+				// the title column does not exist so the default is written
+				Source:  csvData.Source{Record: inputRecord, ColumnName: "title", Header: inputHeader},
+				OnError: mapjitsu.DefaultOnError,
+				Default: "Mx",
+				Target:  csvData.Target{Record: outputRecord, ColumnNumber: 1},
+			},
+			{
+				// the age can not be converted so the mapping is skipped
+				Source:    csvData.Source{Record: inputRecord, ColumnName: "age", Header: inputHeader},
+				Transform: mapjitsu.Pipeline{transforms.ToInt(), transforms.ToString()},
+				OnError:   mapjitsu.SkipOnError,
+				Target:    csvData.Target{Record: outputRecord, ColumnNumber: 2},
+			},
+			{
+				// the age is recorded as an error but the remaining mappings still apply
+				Source:    csvData.Source{Record: inputRecord, ColumnName: "age", Header: inputHeader},
+				Transform: mapjitsu.Pipeline{transforms.ToInt()},
+				OnError:   mapjitsu.CollectOnError,
+				Target:    csvData.Target{Record: outputRecord, ColumnNumber: 3},
+			},
+			{
+				Source: csvData.Source{Record: inputRecord, ColumnName: "last_name", Header: inputHeader},
+				Target: csvData.Target{Record: outputRecord, ColumnNumber: 4},
+			},
+		},
+	}
+
+	err := definition.Apply()
+	var errs mapjitsu.Errors
+	if !errors.As(err, &errs) || len(errs) != 1 {
+		t.Fatalf("expected 1 collected error, got %v", err)
+	}
+	var mappingError *mapjitsu.MappingError
+	if !errors.As(err, &mappingError) || mappingError.Index != 2 {
+		t.Errorf("expected the error to be from mapping 2, got %v", err)
+	}
+
+	expected := "[Mx   Test]"
+	if actual := fmt.Sprint(outputRecord); actual != expected {
+		t.Errorf("resulting output %s does not match expected %s", actual, expected)
+	}
+
+}
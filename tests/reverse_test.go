@@ -0,0 +1,211 @@
+package tests
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"testing"
+
+	"github.com/clbanning/mxj"
+
+	"github.com/8legd/mapjitsu"
+	csvData "github.com/8legd/mapjitsu/csv/data"
+	mxjData "github.com/8legd/mapjitsu/mxj/data"
+	"github.com/8legd/mapjitsu/spec"
+	"github.com/8legd/mapjitsu/transforms"
+)
+
+// Example test mapping inbound then outbound with a reversed Definition
+func TestReverse(t *testing.T) {
+
+	partner := mxj.Map{"user": map[string]interface{}{"first_name": "tim", "age": "42"}}
+	record := []string{"", ""}
+	header := []string{"FirstName", "Age"}
+
+	// age is formatted as a string by the partner
+	formatAge := func(v interface{}) (interface{}, error) {
+		return strconv.Itoa(v.(int)), nil
+	}
+
+	inbound := mapjitsu.Definition{
+		Mappings: []mapjitsu.Mapping{
+			{
+				Source:    mxjData.Source{Map: partner, Path: "user.first_name"},
+				Transform: mapjitsu.Pipeline{transforms.ToUpper()},
+				Inverse:   mapjitsu.Pipeline{transforms.ToLower()},
+				Target:    csvData.Target{Header: header, Record: record, ColumnName: "FirstName"},
+			},
+			{
+				Source:    mxjData.Source{Map: partner, Path: "user.age"},
+				Transform: mapjitsu.Pipeline{transforms.ToInt(), transforms.ToString()},
+				Inverse:   mapjitsu.Pipeline{formatAge, transforms.ToInt()},
+				Target:    mapjitsu.Var("age"),
+			},
+			{
+				Source: mapjitsu.Var("age"),
+				Target: csvData.Target{Header: header, Record: record, ColumnName: "Age"},
+			},
+		},
+	}
+
+	err := inbound.Apply()
+	if err != nil {
+		t.Fatalf("failed to apply inbound definition %v", err)
+	}
+	expected := "[TIM 42]"
+	result := fmt.Sprintf("%v", record)
+	if result != expected {
+		t.Errorf("resulting inbound record does not match expected record \n\n%s\n\n%s", result, expected)
+	}
+
+	outbound, err := inbound.Reverse()
+	if err != nil {
+		t.Fatalf("failed to reverse definition %v", err)
+	}
+
+	// the outbound definition writes our record back to the partner
+	partner["user"] = map[string]interface{}{}
+	record[0] = "TINA"
+	err = outbound.Apply()
+	if err != nil {
+		t.Fatalf("failed to apply outbound definition %v", err)
+	}
+	expected = "map[user:map[age:42 first_name:tina]]"
+	result = fmt.Sprintf("%v", partner)
+	if result != expected {
+		t.Errorf("resulting outbound map does not match expected map \n\n%s\n\n%s", result, expected)
+	}
+
+	// reversing twice maps in the original direction
+	again, err := outbound.Reverse()
+	if err != nil {
+		t.Fatalf("failed to reverse definition %v", err)
+	}
+	err = again.Apply()
+	if err != nil {
+		t.Fatalf("failed to apply reversed outbound definition %v", err)
+	}
+	expected = "[TINA 42]"
+	result = fmt.Sprintf("%v", record)
+	if result != expected {
+		t.Errorf("resulting inbound record does not match expected record \n\n%s\n\n%s", result, expected)
+	}
+}
+
+// Example test reversing a Template
+func TestReverseTemplate(t *testing.T) {
+
+	header := []string{"first_name"}
+	definition := mapjitsu.Definition{
+		Mappings: []mapjitsu.Mapping{
+			{
+				Source: csvData.Column{Header: header, Name: "first_name"},
+				Target: mxjData.Path("Customer.FirstName"),
+			},
+		},
+	}
+	reversed, err := definition.Reverse()
+	if err != nil {
+		t.Fatalf("failed to reverse definition %v", err)
+	}
+	template, err := mapjitsu.Compile(reversed)
+	if err != nil {
+		t.Fatalf("failed to compile template %v", err)
+	}
+
+	record := []string{""}
+	err = template.Execute(mxj.Map{"Customer": map[string]interface{}{"FirstName": "Tim"}}, record)
+	if err != nil {
+		t.Fatalf("failed to execute template %v", err)
+	}
+	if record[0] != "Tim" {
+		t.Errorf("resulting record %v does not match expected record [Tim]", record)
+	}
+}
+
+// Example test reporting mappings which can not be reversed
+func TestReverseErrors(t *testing.T) {
+
+	input := mxj.Map{"user": map[string]interface{}{}}
+	output := mxj.Map{"Customer": map[string]interface{}{}}
+
+	definition := mapjitsu.Definition{
+		Mappings: []mapjitsu.Mapping{
+			{
+				Source: mxjData.Source{Map: input, Path: "user.first_name"},
+				Target: mxjData.Target{Map: output, Path: "Customer.FirstName"},
+			},
+			{
+				// the second step has no inverse
+				Source:    mxjData.Source{Map: input, Path: "user.last_name"},
+				Transform: mapjitsu.Pipeline{transforms.ToUpper(), transforms.TrimSpace()},
+				Inverse:   mapjitsu.Pipeline{transforms.ToLower()},
+				Target:    mxjData.Target{Map: output, Path: "Customer.LastName"},
+			},
+			{
+				// a function can not be written to
+				Source: mapjitsu.SourceFunc(func() (interface{}, error) { return "Mx", nil }),
+				Target: mxjData.Target{Map: output, Path: "Customer.Title"},
+			},
+			{
+				Source: mxjData.Source{Map: input, Path: "user.age"},
+				When:   mapjitsu.Not(mapjitsu.Equals(nil)),
+				Target: mxjData.Target{Map: output, Path: "Customer.Age"},
+			},
+		},
+	}
+
+	_, err := definition.Reverse()
+	var errs mapjitsu.Errors
+	if !errors.As(err, &errs) || len(errs) != 3 {
+		t.Fatalf("expected 3 errors, got %v", err)
+	}
+	expected := []string{
+		"mapping 1 failed at transform 1 with value <nil>: not invertible, no inverse declared",
+		"mapping 2 failed at source: source mapjitsu.SourceFunc can not be reversed",
+		"mapping 3 failed at condition with value <nil>: conditional mappings can not be reversed",
+	}
+	for i, err := range errs {
+		if err.Error() != expected[i] {
+			t.Errorf("resulting error does not match expected error \n\n%s\n\n%s", err, expected[i])
+		}
+	}
+}
+
+// Example test declaring inverse transforms in a specification
+func TestReverseSpec(t *testing.T) {
+
+	registry := spec.NewRegistry()
+	definition, err := registry.LoadYAML([]byte(`
+mappings:
+  - source: {adapter: var, name: first_name}
+    transforms:
+      - {name: upper, inverse: lower}
+    target: {adapter: var, name: FirstName}
+  - source: {adapter: var, name: FirstName}
+    transforms:
+      - {name: trim, inverse: {name: pad_left, args: [4, " "]}}
+    target: {adapter: var, name: Name}
+`))
+	if err != nil {
+		t.Fatalf("failed to load specification %v", err)
+	}
+	_, err = definition.Reverse()
+	if err != nil {
+		t.Errorf("failed to reverse definition %v", err)
+	}
+
+	definition, err = registry.LoadYAML([]byte(`
+mappings:
+  - source: {adapter: var, name: first_name}
+    transforms: [upper]
+    target: {adapter: var, name: FirstName}
+`))
+	if err != nil {
+		t.Fatalf("failed to load specification %v", err)
+	}
+	_, err = definition.Reverse()
+	if err == nil {
+		t.Errorf("expected a transform without an inverse to fail")
+	}
+}
@@ -0,0 +1,129 @@
+package tests
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/8legd/mapjitsu"
+	mxjData "github.com/8legd/mapjitsu/mxj/data"
+	"github.com/8legd/mapjitsu/spec"
+	"github.com/clbanning/mxj"
+)
+
+// Example test loading mappings from a YAML specification
+func TestSpec(t *testing.T) {
+
+	input, err := mxj.NewMapJson([]byte(`{
+		"user": {
+			"first_name": "Tim",
+			"last_name": " Test "
+		}
+	}`))
+	if err != nil {
+		t.Fatalf("failed to unmarshal input %v", err)
+	}
+
+	output := mxj.Map{
+		"Customer": make(map[string]interface{}),
+	}
+
+	// sources and targets are registered by name alongside the builtin transforms
+	registry := spec.NewRegistry()
+	registry.RegisterSource("input", func(args spec.Args) (mapjitsu.Source, error) {
+		path, err := args.String("path")
+		return mxjData.Source{Map: input, Path: path}, err
+	})
+	registry.RegisterTarget("output", func(args spec.Args) (mapjitsu.Target, error) {
+		path, err := args.String("path")
+		return mxjData.Target{Map: output, Path: path}, err
+	})
+
+	definition, err := registry.LoadYAML([]byte(`
+mappings:
+  - name: Customer FirstName
+    source: {adapter: input, path: user.first_name}
+    target: {adapter: output, path: Customer.FirstName}
+  - name: Customer LastName
+    source:
+      adapter: input
+      path: user.last_name
+    transforms:
+      - trim
+    target:
+      adapter: output
+      path: Customer.LastName
+  - source: {adapter: const, value: individual}
+    target: {adapter: output, path: Customer.Type}
+  - source: {adapter: input, path: user.title}
+    on_error: default
+    default: Mx
+    target: {adapter: output, path: Customer.Title}
+`))
+	if err != nil {
+		t.Fatalf("failed to load specification %v", err)
+	}
+
+	err = definition.Apply()
+	if err != nil {
+		t.Fatalf("failed to apply mappings %v", err)
+	}
+
+	var json []byte
+	json, err = output.Json()
+	if err != nil {
+		t.Fatalf("failed to marshal output %v", err)
+	}
+	expected := `{"Customer":{"FirstName":"Tim","LastName":"Test","Title":"Mx","Type":"individual"}}`
+	if string(json) != expected {
+		t.Errorf("resulting json string \n%s\n does not match expected \n%s\n", json, expected)
+	}
+
+	// the same mappings can be given as JSON
+	_, err = registry.LoadJSON([]byte(`{
+	"mappings": [
+		{
+			"source": {"adapter": "input", "path": "user.first_name"},
+			"transforms": [{"name": "trim"}],
+			"target": {"adapter": "output", "path": "Customer.FirstName"}
+		}
+	]
+}`))
+	if err != nil {
+		t.Fatalf("failed to load JSON specification %v", err)
+	}
+
+	// validation errors report the line of each problem
+	_, err = registry.LoadJSON([]byte(`{
+	"mappings": [
+		{
+			"source": {"adapter": "unknown", "path": "user.first_name"},
+			"transforms": ["trim", "shout"],
+			"target": {"adapter": "output"}
+		}
+	]
+}`))
+	var errs mapjitsu.Errors
+	if !errors.As(err, &errs) || len(errs) != 3 {
+		t.Fatalf("expected 3 validation errors, got %v", err)
+	}
+	lines := []int{4, 5, 6}
+	for i, err := range errs {
+		var specError *spec.Error
+		if !errors.As(err, &specError) || specError.Line != lines[i] {
+			t.Errorf("expected error at line %d, got %v", lines[i], err)
+		}
+	}
+	t.Logf("%v", err)
+
+	_, err = registry.LoadJSON([]byte("{\n\t\"mappings\": [\n\t\t{,}\n\t]\n}"))
+	var specError *spec.Error
+	if !errors.As(err, &specError) || specError.Line != 3 {
+		t.Errorf("expected syntax error at line 3, got %v", err)
+	}
+
+	_, err = registry.LoadYAML([]byte("mappings:\n  - name: Customer\n    source: \"input\n"))
+	if !errors.As(err, &specError) || specError.Line != 3 {
+		t.Errorf("expected YAML syntax error at line 3, got %v", err)
+	}
+
+}
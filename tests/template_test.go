@@ -0,0 +1,104 @@
+package tests
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/8legd/mapjitsu"
+	csvData "github.com/8legd/mapjitsu/csv/data"
+	mxjData "github.com/8legd/mapjitsu/mxj/data"
+	"github.com/8legd/mapjitsu/transforms"
+	"github.com/clbanning/mxj"
+)
+
+// Example test compiling a Template once and executing it for each CSV row
+func TestTemplate(t *testing.T) {
+
+	inputHeader := []string{"first_name", "last_name", "dob"}
+	outputHeader := []string{"Customer DOB", "Customer FirstName", "Customer LastName"}
+
+	template, err := mapjitsu.Compile(mapjitsu.Definition{
+		Mappings: []mapjitsu.Mapping{
+			{
+				Source: csvData.Column{Number: 1},
+				Target: csvData.Column{Header: outputHeader, Name: "Customer FirstName"},
+			},
+			{
+				Source:    csvData.Column{Header: inputHeader, Name: "last_name"},
+				Transform: mapjitsu.Pipeline{transforms.ToUpper()},
+				Target:    csvData.Column{Header: outputHeader, Name: "Customer LastName"},
+			},
+			{
+				Source: csvData.Column{Header: inputHeader, Name: "dob"},
+				Target: csvData.Column{Header: outputHeader, Name: "Customer DOB"},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to compile template %v", err)
+	}
+
+	// the template is safe to execute concurrently across rows
+	rows := 100
+	outputs := make([][]string, rows)
+	var wg sync.WaitGroup
+	for row := 0; row < rows; row++ {
+		wg.Add(1)
+		go func(row int) {
+			defer wg.Done()
+			input := []string{fmt.Sprintf("Tim%d", row), "Test", "01/01/2000"}
+			outputs[row] = make([]string, len(outputHeader))
+			if err := template.Execute(input, outputs[row]); err != nil {
+				t.Errorf("failed to execute template at row %d %v", row, err)
+			}
+		}(row)
+	}
+	wg.Wait()
+
+	for row, output := range outputs {
+		expected := fmt.Sprintf("[01/01/2000 Tim%d TEST]", row)
+		if actual := fmt.Sprintf("%v", output); actual != expected {
+			t.Errorf("resulting output %s at row %d does not match expected %s", actual, row, expected)
+		}
+	}
+
+	// invalid columns are reported when compiling
+	_, err = mapjitsu.Compile(mapjitsu.Definition{
+		Mappings: []mapjitsu.Mapping{
+			{
+				Source: csvData.Column{Header: inputHeader, Name: "title"},
+				Target: csvData.Column{Header: outputHeader, Name: "Customer Title"},
+			},
+		},
+	})
+	var errs mapjitsu.Errors
+	if !errors.As(err, &errs) || len(errs) != 2 {
+		t.Fatalf("expected 2 compile errors, got %v", err)
+	}
+	t.Logf("%v", err)
+
+	// templates also work with mxj Maps
+	template, err = mapjitsu.Compile(mapjitsu.Definition{
+		Mappings: []mapjitsu.Mapping{
+			{
+				Source: mxjData.Path("user.first_name"),
+				Target: mxjData.Path("Customer.FirstName"),
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to compile template %v", err)
+	}
+	input := mxj.Map{"user": map[string]interface{}{"first_name": "Tim"}}
+	output := mxj.Map{"Customer": map[string]interface{}{}}
+	err = template.Execute(input, output)
+	if err != nil {
+		t.Fatalf("failed to execute template %v", err)
+	}
+	if actual := output.ValueOrEmptyForPathString("Customer.FirstName"); actual != "Tim" {
+		t.Errorf("resulting Customer.FirstName %q does not match expected %q", actual, "Tim")
+	}
+
+}
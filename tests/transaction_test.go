@@ -0,0 +1,175 @@
+package tests
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/8legd/mapjitsu"
+	csvData "github.com/8legd/mapjitsu/csv/data"
+	mxjData "github.com/8legd/mapjitsu/mxj/data"
+	"github.com/clbanning/mxj"
+)
+
+// Example test applying mappings all or nothing
+func TestTransactional(t *testing.T) {
+
+	input, err := mxj.NewMapJson([]byte(`{
+		"user": {
+			"first_name": "Tim",
+			"last_name": "Test"
+		}
+	}`))
+	if err != nil {
+		t.Fatalf("failed to unmarshal input %v", err)
+	}
+
+	output := mxj.Map{
+		"Customer": map[string]interface{}{
+			"FirstName": "Timothy",
+		},
+	}
+	outputRecord := []string{"", ""}
+
+	errFailed := errors.New("billing system unavailable")
+	fail := false
+
+	definition := mapjitsu.Definition{
+		Transactional: true,
+		Mappings: []mapjitsu.Mapping{
+			{
+				Source: mxjData.Source{Map: input, Path: "user.first_name"},
+				Target: mxjData.Target{Map: output, Path: "Customer.FirstName"},
+			},
+			{
+				Source: mxjData.Source{Map: input, Path: "user.last_name"},
+				Target: mxjData.Target{Map: output, Path: "Customer.LastName"},
+			},
+			{
+				Source: mxjData.Source{Map: input, Path: "user.first_name"},
+				Target: csvData.Target{Record: outputRecord, ColumnNumber: 1},
+			},
+			{
+				// this target does not support staging so is only written at commit
+				Source: mxjData.Source{Map: input, Path: "user.last_name"},
+				Target: mapjitsu.TargetFunc(func(v interface{}) error {
+					if fail {
+						return errFailed
+					}
+					return nil
+				}),
+			},
+			{
+				Source: mxjData.Source{Map: input, Path: "user.title"},
+				Target: mxjData.Target{Map: output, Path: "Customer.Title"},
+			},
+		},
+	}
+
+	assertUnchanged := func() {
+		json, err := output.Json()
+		if err != nil {
+			t.Fatalf("failed to marshal output %v", err)
+		}
+		if string(json) != `{"Customer":{"FirstName":"Timothy"}}` {
+			t.Errorf("expected output to be unchanged, got %s", json)
+		}
+		if outputRecord[0] != "" {
+			t.Errorf("expected output record to be unchanged, got %v", outputRecord)
+		}
+	}
+
+	// user.title does not exist so nothing is written
+	err = definition.Apply()
+	if err == nil {
+		t.Fatalf("expected an error applying mappings")
+	}
+	assertUnchanged()
+
+	// a failure while committing rolls back the writes already made
+	definition.Mappings = definition.Mappings[:4]
+	fail = true
+	err = definition.Apply()
+	if !errors.Is(err, errFailed) {
+		t.Fatalf("expected %v, got %v", errFailed, err)
+	}
+	assertUnchanged()
+
+	fail = false
+	err = definition.Apply()
+	if err != nil {
+		t.Fatalf("failed to apply mappings %v", err)
+	}
+	json, err := output.Json()
+	if err != nil {
+		t.Fatalf("failed to marshal output %v", err)
+	}
+	if string(json) != `{"Customer":{"FirstName":"Tim","LastName":"Test"}}` || outputRecord[0] != "Tim" {
+		t.Errorf("expected all mappings to be applied, got %s %v", json, outputRecord)
+	}
+
+}
+
+// Example test rolling back a write merged into an existing map
+func TestTransactionalDeepMerge(t *testing.T) {
+
+	errFailed := errors.New("billing system unavailable")
+	output := mxj.Map{"Customer": map[string]interface{}{"Name": "Tim"}}
+	definition := mapjitsu.Definition{
+		Transactional: true,
+		Mappings: []mapjitsu.Mapping{
+			{
+				Source: mapjitsu.SourceFunc(func() (interface{}, error) { return map[string]interface{}{"Age": "40"}, nil }),
+				Target: mxjData.Target{Map: output, Path: "Customer", Strategy: mxjData.DeepMerge},
+			},
+			{
+				Source: mapjitsu.SourceFunc(func() (interface{}, error) { return "Test", nil }),
+				Target: mapjitsu.TargetFunc(func(interface{}) error { return errFailed }),
+			},
+		},
+	}
+
+	err := definition.Apply()
+	if !errors.Is(err, errFailed) {
+		t.Fatalf("expected %v, got %v", errFailed, err)
+	}
+	json, err := output.Json()
+	if err != nil {
+		t.Fatalf("failed to marshal output %v", err)
+	}
+	if string(json) != `{"Customer":{"Name":"Tim"}}` {
+		t.Errorf("expected output to be unchanged, got %s", json)
+	}
+}
+
+// Example test skipping a write which fails while committing
+func TestTransactionalSkipOnError(t *testing.T) {
+
+	errFailed := errors.New("billing system unavailable")
+	output := mxj.Map{"Customer": map[string]interface{}{"Name": ""}}
+	definition := mapjitsu.Definition{
+		Transactional: true,
+		Mappings: []mapjitsu.Mapping{
+			{
+				Source: mapjitsu.SourceFunc(func() (interface{}, error) { return "Tim", nil }),
+				Target: mxjData.Target{Map: output, Path: "Customer.Name"},
+			},
+			{
+				Source:  mapjitsu.SourceFunc(func() (interface{}, error) { return "Test", nil }),
+				OnError: mapjitsu.SkipOnError,
+				Target:  mapjitsu.TargetFunc(func(interface{}) error { return errFailed }),
+			},
+		},
+	}
+
+	err := definition.Apply()
+	if err != nil {
+		t.Fatalf("failed to apply mappings %v", err)
+	}
+	json, err := output.Json()
+	if err != nil {
+		t.Fatalf("failed to marshal output %v", err)
+	}
+	if string(json) != `{"Customer":{"Name":"Tim"}}` {
+		t.Errorf("expected the remaining writes to be committed, got %s", json)
+	}
+}
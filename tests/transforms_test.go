@@ -0,0 +1,110 @@
+package tests
+
+import (
+	"math"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/8legd/mapjitsu"
+	"github.com/8legd/mapjitsu/transforms"
+)
+
+func TestTransforms(t *testing.T) {
+
+	tests := []struct {
+		name      string
+		transform func(interface{}) (interface{}, error)
+		input     interface{}
+		expected  interface{}
+		fails     bool
+	}{
+		{"ToString nil", transforms.ToString(), nil, "", false},
+		{"ToString int", transforms.ToString(), 42, "42", false},
+		{"ToString bytes", transforms.ToString(), []byte("abc"), "abc", false},
+		{"ToInt string", transforms.ToInt(), " 42 ", 42, false},
+		{"ToInt float", transforms.ToInt(), 42.0, 42, false},
+		{"ToInt fractional float", transforms.ToInt(), 42.5, nil, true},
+		{"ToInt float out of range", transforms.ToInt(), float64(1 << 63), nil, true},
+		{"ToInt large float", transforms.ToInt(), 1e20, nil, true},
+		{"ToInt float minimum", transforms.ToInt(), float64(math.MinInt64), math.MinInt64, false},
+		{"ToInt uint8", transforms.ToInt(), uint8(7), 7, false},
+		{"ToInt bool", transforms.ToInt(), true, 1, false},
+		{"ToInt invalid string", transforms.ToInt(), "abc", nil, true},
+		{"ToInt nil", transforms.ToInt(), nil, nil, true},
+		{"ToFloat string", transforms.ToFloat(), "1.5", 1.5, false},
+		{"ToFloat int", transforms.ToFloat(), 2, 2.0, false},
+		{"ToFloat invalid", transforms.ToFloat(), []int{}, nil, true},
+		{"ToBool string", transforms.ToBool(), "true", true, false},
+		{"ToBool number", transforms.ToBool(), 0, false, false},
+		{"ToBool invalid string", transforms.ToBool(), "maybe", nil, true},
+		{"TrimSpace", transforms.TrimSpace(), "  Tim ", "Tim", false},
+		{"TrimSpace not a string", transforms.TrimSpace(), 1, nil, true},
+		{"Trim", transforms.Trim("-"), "--Tim-", "Tim", false},
+		{"ToUpper", transforms.ToUpper(), "Tim", "TIM", false},
+		{"ToLower", transforms.ToLower(), "Tim", "tim", false},
+		{"Default nil", transforms.Default("none"), nil, "none", false},
+		{"Default empty", transforms.Default("none"), "", "", false},
+		{"DefaultIfEmpty empty", transforms.DefaultIfEmpty("none"), "", "none", false},
+		{"DefaultIfEmpty value", transforms.DefaultIfEmpty("none"), "Tim", "Tim", false},
+		{"ReplaceRegexp", transforms.ReplaceRegexp(regexp.MustCompile(`\D`), ""), "(08) 9000 0000", "0890000000", false},
+		{"ExtractRegexp", transforms.ExtractRegexp(regexp.MustCompile(`(\d{4})$`), 1), "Perth WA 6000", "6000", false},
+		{"ExtractRegexp no match", transforms.ExtractRegexp(regexp.MustCompile(`(\d{4})$`), 1), "Perth", nil, true},
+		{"ExtractRegexp invalid group", transforms.ExtractRegexp(regexp.MustCompile(`\d{4}$`), 1), "6000", nil, true},
+		{"Substring", transforms.Substring(1, 3), "Åland", "lan", false},
+		{"Substring rest", transforms.Substring(2, -1), "Tim Test", "m Test", false},
+		{"Substring past end", transforms.Substring(10, 2), "Tim", "", false},
+		{"PadLeft", transforms.PadLeft(4, '0'), "800", "0800", false},
+		{"PadLeft wide enough", transforms.PadLeft(2, '0'), "800", "800", false},
+		{"PadRight", transforms.PadRight(5, '.'), "Tim", "Tim..", false},
+		{"FormatNumber float", transforms.FormatNumber(2), 3.14159, "3.14", false},
+		{"FormatNumber string", transforms.FormatNumber(1), "2", "2.0", false},
+		{"FormatNumber bool", transforms.FormatNumber(1), true, nil, true},
+		{"OneOf match", transforms.OneOf("individual", "business"), "business", "business", false},
+		{"OneOf no match", transforms.OneOf("individual", "business"), "other", nil, true},
+	}
+
+	for _, test := range tests {
+		actual, err := test.transform(test.input)
+		if test.fails {
+			if err == nil {
+				t.Errorf("%s: expected an error transforming %#v, got %#v", test.name, test.input, actual)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: failed to transform %#v %v", test.name, test.input, err)
+			continue
+		}
+		if !reflect.DeepEqual(actual, test.expected) {
+			t.Errorf("%s: resulting value %#v does not match expected %#v", test.name, actual, test.expected)
+		}
+	}
+
+	// out of range floats are reported as such, rather than as fractions
+	_, err := transforms.ToInt()(1e20)
+	if err == nil || !strings.Contains(err.Error(), "out of range") {
+		t.Errorf("expected an out of range error, got %v", err)
+	}
+
+	// transforms compose in a Pipeline
+	var actual interface{}
+	definition := mapjitsu.Definition{
+		Mappings: []mapjitsu.Mapping{
+			{
+				Source:    mapjitsu.SourceFunc(func() (interface{}, error) { return " 800 ", nil }),
+				Transform: mapjitsu.Pipeline{transforms.TrimSpace(), transforms.PadLeft(4, '0')},
+				Target:    mapjitsu.TargetFunc(func(v interface{}) error { actual = v; return nil }),
+			},
+		},
+	}
+	err = definition.Apply()
+	if err != nil {
+		t.Fatalf("failed to apply mappings %v", err)
+	}
+	if actual != "0800" {
+		t.Errorf("resulting value %#v does not match expected %#v", actual, "0800")
+	}
+
+}
@@ -0,0 +1,188 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"math"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/8legd/mapjitsu"
+	csvData "github.com/8legd/mapjitsu/csv/data"
+	"github.com/8legd/mapjitsu/validators"
+)
+
+// Example test reporting every failing field in a record
+func TestValidation(t *testing.T) {
+
+	inputHeader := []string{"first_name", "last_name", "dob", "postcode", "state"}
+	inputRecord := []string{"Tim", "", "31/12/1899", "60000", "WA"}
+	outputRecord := []string{"", "", "", "", ""}
+
+	dob, _ := time.Parse("02/01/2006", "01/01/1900")
+
+	column := func(name string) csvData.Source {
+		return csvData.Source{Record: inputRecord, ColumnName: name, Header: inputHeader}
+	}
+
+	definition := mapjitsu.Definition{
+		Mappings: []mapjitsu.Mapping{
+			{
+				Name:     "Customer FirstName",
+				Source:   column("first_name"),
+				Validate: []mapjitsu.Validator{validators.NotEmpty(), validators.Length(1, 50)},
+				Target:   csvData.Target{Record: outputRecord, ColumnNumber: 1},
+			},
+			{
+				Name:     "Customer LastName",
+				Source:   column("last_name"),
+				Validate: []mapjitsu.Validator{validators.NotEmpty(), validators.Length(1, 50)},
+				Target:   csvData.Target{Record: outputRecord, ColumnNumber: 2},
+			},
+			{
+				Name:     "Customer DOB",
+				Source:   column("dob"),
+				Validate: []mapjitsu.Validator{validators.DateRange("02/01/2006", dob, time.Time{})},
+				Target:   csvData.Target{Record: outputRecord, ColumnNumber: 3},
+			},
+			{
+				Name:   "Customer Postcode",
+				Source: column("postcode"),
+				Validate: []mapjitsu.Validator{
+					validators.Regexp(regexp.MustCompile(`^\d{4}$`)),
+					validators.Range(200, 9999),
+				},
+				Target: csvData.Target{Record: outputRecord, ColumnNumber: 4},
+			},
+			{
+				Name:     "Customer State",
+				Source:   column("state"),
+				Validate: []mapjitsu.Validator{validators.Enum("ACT", "NSW", "NT", "QLD", "SA", "TAS", "VIC", "WA")},
+				Target:   csvData.Target{Record: outputRecord, ColumnNumber: 5},
+			},
+		},
+	}
+
+	err := definition.Apply()
+	var report *mapjitsu.ValidationReport
+	if !errors.As(err, &report) {
+		t.Fatalf("expected *mapjitsu.ValidationReport, got %T %v", err, err)
+	}
+
+	expected := []struct {
+		field string
+		rule  string
+		value interface{}
+	}{
+		{"Customer LastName", "not-empty", ""},
+		{"Customer LastName", "length", ""},
+		{"Customer DOB", "date-range", "31/12/1899"},
+		{"Customer Postcode", "regexp", "60000"},
+		{"Customer Postcode", "range", "60000"},
+	}
+	if len(report.Failures) != len(expected) {
+		t.Fatalf("expected %d failures, got %v", len(expected), report)
+	}
+	for i, failure := range report.Failures {
+		if failure.Field != expected[i].field || failure.Rule != expected[i].rule || failure.Value != expected[i].value {
+			t.Errorf("resulting failure %+v does not match expected %+v", failure, expected[i])
+		}
+	}
+
+	// only valid fields are written
+	if outputRecord[0] != "Tim" || outputRecord[1] != "" || outputRecord[4] != "WA" {
+		t.Errorf("expected only valid fields to be written, got %v", outputRecord)
+	}
+	t.Logf("%v", err)
+
+}
+
+// Example test reporting validation failures found before a mapping fails
+func TestValidationFailed(t *testing.T) {
+
+	errFailed := errors.New("source unavailable")
+	outputRecord := []string{"", ""}
+
+	definition := mapjitsu.Definition{
+		Mappings: []mapjitsu.Mapping{
+			{
+				Name:     "Customer LastName",
+				Source:   mapjitsu.SourceFunc(func() (interface{}, error) { return "", nil }),
+				Validate: []mapjitsu.Validator{validators.NotEmpty()},
+				Target:   csvData.Target{Record: outputRecord, ColumnNumber: 1},
+			},
+			{
+				Name:   "Customer State",
+				Source: mapjitsu.SourceFunc(func() (interface{}, error) { return nil, errFailed }),
+				Target: csvData.Target{Record: outputRecord, ColumnNumber: 2},
+			},
+		},
+	}
+
+	apply := map[string]func() error{
+		"Apply": definition.Apply,
+		"ApplyConcurrent": func() error {
+			return definition.ApplyConcurrent(context.Background(), 1)
+		},
+	}
+	for name, apply := range apply {
+		err := apply()
+		if !errors.Is(err, errFailed) {
+			t.Errorf("%s: expected %v, got %v", name, errFailed, err)
+		}
+		var report *mapjitsu.ValidationReport
+		if !errors.As(err, &report) || len(report.Failures) != 1 || report.Failures[0].Field != "Customer LastName" {
+			t.Errorf("%s: expected the validation failure to be reported, got %v", name, err)
+		}
+	}
+
+}
+
+func TestValidators(t *testing.T) {
+
+	from, _ := time.Parse("2006-01-02", "2000-01-01")
+	to, _ := time.Parse("2006-01-02", "2000-12-31")
+
+	tests := []struct {
+		name      string
+		validator mapjitsu.Validator
+		input     interface{}
+		valid     bool
+	}{
+		{"Required nil", validators.Required(), nil, false},
+		{"Required empty", validators.Required(), "", true},
+		{"NotEmpty blank", validators.NotEmpty(), "  ", false},
+		{"NotEmpty slice", validators.NotEmpty(), []interface{}{}, false},
+		{"NotEmpty number", validators.NotEmpty(), 0, true},
+		{"Regexp match", validators.Regexp(regexp.MustCompile(`^\d+$`)), "123", true},
+		{"Regexp nil", validators.Regexp(regexp.MustCompile(`^\d+$`)), nil, true},
+		{"Regexp not a string", validators.Regexp(regexp.MustCompile(`^\d+$`)), 123, false},
+		{"Range int", validators.Range(1, 10), 10, true},
+		{"Range float", validators.Range(1, 10), 10.5, false},
+		{"Range string", validators.Range(1, 10), "abc", false},
+		{"Range NaN", validators.Range(1, 10), "NaN", false},
+		{"Range NaN float", validators.Range(1, 10), math.NaN(), false},
+		{"Length characters", validators.Length(1, 5), "Åland", true},
+		{"Length unlimited", validators.Length(1, -1), "Tim Test", true},
+		{"Length slice", validators.Length(1, 2), []interface{}{1, 2, 3}, false},
+		{"Length invalid type", validators.Length(1, 2), 12, false},
+		{"Enum match", validators.Enum("a", "b"), "b", true},
+		{"Enum no match", validators.Enum("a", "b"), "c", false},
+		{"DateRange in range", validators.DateRange("2006-01-02", from, to), "2000-06-01", true},
+		{"DateRange after", validators.DateRange("2006-01-02", from, to), "2001-01-01", false},
+		{"DateRange open", validators.DateRange("2006-01-02", from, time.Time{}), to.AddDate(10, 0, 0), true},
+		{"DateRange invalid", validators.DateRange("2006-01-02", from, to), "01/06/2000", false},
+	}
+
+	for _, test := range tests {
+		err := test.validator.Check(test.input)
+		if test.valid && err != nil {
+			t.Errorf("%s: expected %#v to be valid, got %v", test.name, test.input, err)
+		}
+		if !test.valid && err == nil {
+			t.Errorf("%s: expected %#v to be invalid", test.name, test.input)
+		}
+	}
+
+}
@@ -0,0 +1,90 @@
+package tests
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/8legd/mapjitsu"
+	mxjData "github.com/8legd/mapjitsu/mxj/data"
+	"github.com/clbanning/mxj"
+)
+
+// Example test building a derived field from variables holding already cleaned values
+func TestVars(t *testing.T) {
+
+	input, err := mxj.NewMapJson([]byte(`{
+		"user": {
+			"first_name": "  Tim ",
+			"last_name": "Test  "
+		}
+	}`))
+	if err != nil {
+		t.Fatalf("failed to unmarshal input %v", err)
+	}
+
+	output := mxj.Map{
+		"Customer": make(map[string]interface{}),
+	}
+
+	trim := func(v interface{}) (interface{}, error) {
+		s, _ := v.(string)
+		return strings.TrimSpace(s), nil
+	}
+
+	definition := mapjitsu.Definition{
+		Mappings: []mapjitsu.Mapping{
+			{
+				// this mapping is listed first but is applied once both variables are set
+				Source: mapjitsu.Computed{
+					Vars: []string{"first_name", "last_name"},
+					Func: func(vars map[string]interface{}) (interface{}, error) {
+						return vars["first_name"].(string) + " " + vars["last_name"].(string), nil
+					},
+				},
+				Target: mxjData.Target{Map: output, Path: "Customer.FullName"},
+			},
+			{
+				Source: mapjitsu.Var("first_name"),
+				Target: mxjData.Target{Map: output, Path: "Customer.FirstName"},
+			},
+			{
+				Source:    mxjData.Source{Map: input, Path: "user.first_name"},
+				Transform: mapjitsu.Pipeline{trim},
+				Target:    mapjitsu.Var("first_name"),
+			},
+			{
+				Source:    mxjData.Source{Map: input, Path: "user.last_name"},
+				Transform: mapjitsu.Pipeline{trim},
+				Target:    mapjitsu.Var("last_name"),
+			},
+		},
+	}
+
+	err = definition.Apply()
+	if err != nil {
+		t.Fatalf("failed to apply mappings %v", err)
+	}
+
+	if actual := output.ValueOrEmptyForPathString("Customer.FullName"); actual != "Tim Test" {
+		t.Errorf("resulting Customer.FullName %q does not match expected %q", actual, "Tim Test")
+	}
+	if actual := output.ValueOrEmptyForPathString("Customer.FirstName"); actual != "Tim" {
+		t.Errorf("resulting Customer.FirstName %q does not match expected %q", actual, "Tim")
+	}
+
+	// mappings which depend on each other are reported as a cycle
+	definition = mapjitsu.Definition{
+		Mappings: []mapjitsu.Mapping{
+			{Source: mapjitsu.Var("a"), Target: mapjitsu.Var("b")},
+			{Source: mapjitsu.Var("b"), Target: mapjitsu.Var("a")},
+		},
+	}
+	err = definition.Apply()
+	var cycleError *mapjitsu.CycleError
+	if !errors.As(err, &cycleError) {
+		t.Fatalf("expected *mapjitsu.CycleError, got %T %v", err, err)
+	}
+	t.Logf("%v", err)
+
+}
@@ -0,0 +1,244 @@
+package tests
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/clbanning/mxj"
+
+	"github.com/8legd/mapjitsu"
+	mxjData "github.com/8legd/mapjitsu/mxj/data"
+	"github.com/8legd/mapjitsu/transforms"
+)
+
+// Example test with XML input and output using MXJ
+func TestXML(t *testing.T) {
+
+	// NewMapXmlSeq records attributes and the order of elements
+	input, err := mxj.NewMapXmlSeq([]byte(`<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/">
+	<soap:Body>
+		<Policy number="P-1">
+			<Holder title="Mr">Tim Test</Holder>
+			<Vehicle><Make>Ford</Make><Year>2010</Year></Vehicle>
+			<Vehicle><Make>Kia</Make><Year>2020</Year></Vehicle>
+			<Note>Renewal<Code>R1</Code></Note>
+		</Policy>
+	</soap:Body>
+</soap:Envelope>`))
+	if err != nil {
+		t.Fatalf("failed to unmarshal input %v", err)
+	}
+	output := mxj.Map{}
+
+	policy := "soap:Envelope.soap:Body.Policy"
+	definition := mapjitsu.Definition{
+		Mappings: []mapjitsu.Mapping{
+			{
+				// declare the namespace first so it is the first attribute
+				Source: mxjData.XMLSource{Map: input, Path: "soap:Envelope.@xmlns:soap"},
+				Target: mxjData.XMLTarget{Map: output, Path: "ins:Certificate.@xmlns:ins"},
+			},
+			{
+				Source: mxjData.XMLSource{Map: input, Path: policy + ".@number"},
+				Target: mxjData.XMLTarget{Map: output, Path: "ins:Certificate.@ref"},
+			},
+			{
+				Source: mxjData.XMLSource{Map: input, Path: policy + ".Holder"},
+				Target: mxjData.XMLTarget{Map: output, Path: "ins:Certificate.ins:Name"},
+			},
+			{
+				Source: mxjData.XMLSource{Map: input, Path: policy + ".Holder.@title"},
+				Target: mxjData.XMLTarget{Map: output, Path: "ins:Certificate.ins:Name.@title"},
+			},
+			{
+				Source:    mxjData.XMLSource{Map: input, Path: policy + ".Vehicle[1].Make"},
+				Transform: mapjitsu.Pipeline{transforms.ToUpper()},
+				Target:    mxjData.XMLTarget{Map: output, Path: "ins:Certificate.ins:Vehicle[0]"},
+			},
+			{
+				Source: mxjData.XMLSource{Map: input, Path: policy + ".Vehicle[0].Make"},
+				Target: mxjData.XMLTarget{Map: output, Path: "ins:Certificate.ins:Vehicle[1]"},
+			},
+			{
+				Source: mxjData.XMLSource{Map: input, Path: policy + ".Note.#text"},
+				Target: mxjData.XMLTarget{Map: output, Path: "ins:Certificate.ins:Comment"},
+			},
+		},
+	}
+
+	err = definition.Apply()
+	if err != nil {
+		t.Fatalf("failed to apply definition %v", err)
+	}
+
+	xml, err := mxjData.EncodeXML(output)
+	if err != nil {
+		t.Fatalf("failed to marshal output %v", err)
+	}
+	expected := `<ins:Certificate xmlns:ins="http://schemas.xmlsoap.org/soap/envelope/" ref="P-1">` +
+		`<ins:Name title="Mr">Tim Test</ins:Name>` +
+		`<ins:Vehicle>KIA</ins:Vehicle><ins:Vehicle>Ford</ins:Vehicle>` +
+		`<ins:Comment>Renewal</ins:Comment>` +
+		`</ins:Certificate>`
+	if string(xml) != expected {
+		t.Errorf("resulting xml string \n%s\n does not match expected \n%s\n", xml, expected)
+	}
+
+	// repeated elements need an index
+	_, err = mxjData.XMLSource{Map: input, Path: policy + ".Vehicle.Make"}.Value()
+	expectedError := "failed to return " + policy + ".Vehicle.Make " + policy + ".Vehicle is repeated, expected an index"
+	if err == nil || err.Error() != expectedError {
+		t.Errorf("resulting error does not match expected error \n\n%v\n\n%s", err, expectedError)
+	}
+
+	_, err = mxjData.XMLSource{Map: input, Path: policy + ".@status"}.Value()
+	if !errors.Is(err, mapjitsu.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
+// Example test mapping repeated XML elements with a Template
+func TestXMLForEach(t *testing.T) {
+
+	input, err := mxj.NewMapXmlSeq([]byte(`<Order id="A1">
+	<Line sku="x"><Qty>1</Qty></Line>
+	<Line sku="y"><Qty>2</Qty></Line>
+</Order>`))
+	if err != nil {
+		t.Fatalf("failed to unmarshal input %v", err)
+	}
+
+	item, err := mapjitsu.Compile(mapjitsu.Definition{
+		Mappings: []mapjitsu.Mapping{
+			{
+				// paths are relative to the Line element and the Item written
+				Source: mxjData.XMLPath("Qty"),
+				Target: mxjData.XMLPath("@quantity"),
+			},
+			{
+				Source: mxjData.XMLPath("@sku"),
+				Target: mxjData.XMLPath("SKU"),
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to compile template %v", err)
+	}
+
+	output := mxj.Map{}
+	definition := mapjitsu.Definition{
+		Mappings: []mapjitsu.Mapping{
+			{
+				Source: mxjData.XMLSource{Map: input, Path: "Order.@id"},
+				Target: mxjData.XMLTarget{Map: output, Path: "Invoice.@order"},
+			},
+			{
+				Source: mapjitsu.ForEach{Source: mxjData.XMLSource{Map: input, Path: "Order.Line"}, Template: item},
+				Target: mxjData.XMLTarget{Map: output, Path: "Invoice.Item"},
+			},
+		},
+	}
+
+	err = definition.Apply()
+	if err != nil {
+		t.Fatalf("failed to apply definition %v", err)
+	}
+
+	xml, err := mxjData.EncodeXML(output)
+	if err != nil {
+		t.Fatalf("failed to marshal output %v", err)
+	}
+	expected := `<Invoice order="A1"><Item quantity="1"><SKU>x</SKU></Item><Item quantity="2"><SKU>y</SKU></Item></Invoice>`
+	if string(xml) != expected {
+		t.Errorf("resulting xml string \n%s\n does not match expected \n%s\n", xml, expected)
+	}
+}
+
+// Example test writing XML special characters
+func TestXMLEscape(t *testing.T) {
+
+	output := mxj.Map{}
+	values := []struct {
+		path  string
+		value interface{}
+	}{
+		{"Policy.@number", `a"b`},
+		{"Policy.Holder", "AT&T <Corp>"},
+		{"Policy.Party", map[string]interface{}{"Name": "A & B"}},
+	}
+	for _, v := range values {
+		err := mxjData.XMLTarget{Map: output, Path: v.path}.SetValue(v.value)
+		if err != nil {
+			t.Fatalf("failed to set %s %v", v.path, err)
+		}
+	}
+
+	xml, err := mxjData.EncodeXML(output)
+	if err != nil {
+		t.Fatalf("failed to marshal output %v", err)
+	}
+	expected := `<Policy number="a&quot;b"><Holder>AT&amp;T &lt;Corp&gt;</Holder><Party><Name>A &amp; B</Name></Party></Policy>`
+	if string(xml) != expected {
+		t.Errorf("resulting xml string \n%s\n does not match expected \n%s\n", xml, expected)
+	}
+
+	// values read back are unescaped, both from the output and once decoded
+	input, err := mxj.NewMapXmlSeq(xml)
+	if err != nil {
+		t.Fatalf("failed to unmarshal output %v", err)
+	}
+	expectedValues := map[string]string{"Policy.@number": `a"b`, "Policy.Holder": "AT&T <Corp>", "Policy.Party.Name": "A & B"}
+	for _, m := range []mxj.Map{output, input} {
+		for path, expected := range expectedValues {
+			v, err := mxjData.XMLSource{Map: m, Path: path}.Value()
+			if err != nil || v != expected {
+				t.Errorf("resulting %s value %v does not match expected value %s %v", path, v, expected, err)
+			}
+		}
+	}
+}
+
+// Example test rolling back XML writes
+func TestXMLTransactional(t *testing.T) {
+
+	output, err := mxj.NewMapXmlSeq([]byte(`<Order id="A1"><Item sku="x">1</Item><Item sku="y">2</Item><Note>none</Note></Order>`))
+	if err != nil {
+		t.Fatalf("failed to unmarshal output %v", err)
+	}
+	before, err := mxjData.EncodeXML(output)
+	if err != nil {
+		t.Fatalf("failed to marshal output %v", err)
+	}
+
+	value := func(v interface{}) mapjitsu.Source {
+		return mapjitsu.SourceFunc(func() (interface{}, error) { return v, nil })
+	}
+	definition := mapjitsu.Definition{
+		Transactional: true,
+		Mappings: []mapjitsu.Mapping{
+			{Source: value("B2"), Target: mxjData.XMLTarget{Map: output, Path: "Order.@id"}},
+			{Source: value("urgent"), Target: mxjData.XMLTarget{Map: output, Path: "Order.@priority"}},
+			{Source: value("z"), Target: mxjData.XMLTarget{Map: output, Path: "Order.Item[1].@sku"}},
+			{Source: value("3"), Target: mxjData.XMLTarget{Map: output, Path: "Order.Item[2]"}},
+			{Source: value("gift"), Target: mxjData.XMLTarget{Map: output, Path: "Order.Note"}},
+			{Source: value("Tim"), Target: mxjData.XMLTarget{Map: output, Path: "Order.Customer.Name"}},
+			{Source: value([]interface{}{"a", "b"}), Target: mxjData.XMLTarget{Map: output, Path: "Order.Tag"}},
+			{
+				Source: value("Test"),
+				Target: mapjitsu.TargetFunc(func(interface{}) error { return errors.New("unavailable") }),
+			},
+		},
+	}
+
+	err = definition.Apply()
+	if err == nil {
+		t.Fatalf("expected the definition to fail")
+	}
+	after, err := mxjData.EncodeXML(output)
+	if err != nil {
+		t.Fatalf("failed to marshal output %v", err)
+	}
+	if string(after) != string(before) {
+		t.Errorf("expected output to be rolled back to \n%s\n got \n%s\n", before, after)
+	}
+}
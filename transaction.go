@@ -0,0 +1,123 @@
+package mapjitsu
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// StagingTarget is an optional interface implemented by Targets which can
+// stage a write to be committed later. It is used by a Transactional
+// Definition so writes can be undone if a later write fails.
+type StagingTarget interface {
+	Stage(ctx context.Context, v interface{}) (Staged, error)
+}
+
+// Staged is a staged write. Rollback undoes the write if it has been
+// committed and otherwise discards it.
+type Staged interface {
+	Commit() error
+	Rollback() error
+}
+
+// staging records a write to be made when the transaction is committed
+type staging struct {
+	ctx    context.Context
+	index  int
+	m      Mapping
+	v      interface{}
+	staged Staged // nil for Targets which are not a StagingTarget
+}
+
+type transaction struct {
+	writes []staging
+}
+
+// stage is passed to run in place of Mapping.set
+func (tx *transaction) stage(m Mapping, ctx context.Context, index int, v interface{}) error {
+	if _, ok := m.Target.(VarWriter); ok {
+		return m.set(ctx, index, v) // variables are scratch values so are set immediately
+	}
+	w := staging{ctx: ctx, index: index, m: m, v: v}
+	if st, ok := m.Target.(StagingTarget); ok {
+		staged, err := st.Stage(ctx, v)
+		if err != nil {
+			return m.error(index, StageTarget, 0, v, err)
+		}
+		w.staged = staged
+	}
+	tx.writes = append(tx.writes, w)
+	return nil
+}
+
+// commit makes each staged write, rolling back if any fail
+func (tx *transaction) commit() error {
+	for i, w := range tx.writes {
+		err := w.commit()
+		if err != nil && w.m.recovers(err) {
+			continue // skipped, leaving the Target unchanged
+		}
+		if err != nil {
+			// undo the writes already committed, most recent first
+			var errs Errors
+			for j := i - 1; j >= 0; j-- {
+				if staged := tx.writes[j].staged; staged != nil {
+					if rerr := staged.Rollback(); rerr != nil {
+						errs = append(errs, fmt.Errorf("failed to roll back mapping %d %v", tx.writes[j].index, rerr))
+					}
+				}
+			}
+			tx.discard(i)
+			if len(errs) > 0 {
+				return append(Errors{err}, errs...)
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+func (w staging) commit() error {
+	if w.staged == nil {
+		return w.m.set(w.ctx, w.index, w.v)
+	}
+	hooks := runHooks(w.ctx)
+	var start time.Time
+	if hooks != nil {
+		hooks.BeforeTarget(HookEvent{Index: w.index, Mapping: w.m, Stage: StageTarget, Value: w.v})
+		start = time.Now()
+	}
+	err := w.staged.Commit()
+	if err != nil {
+		mappingError := w.m.error(w.index, StageTarget, 0, w.v, err)
+		if hooks != nil && !w.m.recovers(mappingError) {
+			hooks.OnError(w.m.event(w.index, w.v, mappingError), mappingError)
+		}
+		return mappingError
+	}
+	if hooks != nil {
+		hooks.AfterTarget(HookEvent{Index: w.index, Mapping: w.m, Stage: StageTarget, Value: w.v, Duration: time.Since(start)})
+	}
+	return nil
+}
+
+// discard rolls back the uncommitted writes from index from
+func (tx *transaction) discard(from int) {
+	for _, w := range tx.writes[from:] {
+		if w.staged != nil {
+			w.staged.Rollback()
+		}
+	}
+}
+
+// applyTransaction applies the Mappings in order, staging every write and
+// only committing them once all the Mappings have succeeded
+func (d Definition) applyTransaction(ctx context.Context, order []int) error {
+	tx := &transaction{}
+	err := d.runOrder(ctx, order, tx.stage)
+	if err != nil {
+		tx.discard(0)
+		return err
+	}
+	return tx.commit()
+}
@@ -0,0 +1,306 @@
+// Package transforms provides constructors for common Pipeline functions e.g.
+//
+//	Transform: mapjitsu.Pipeline{transforms.Default(""), transforms.TrimSpace(), transforms.ToUpper()}
+//
+// Transforms are described in lineage by the call of their constructor
+// e.g. transforms.Trim("-"), see mapjitsu.Describe.
+package transforms
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/8legd/mapjitsu"
+)
+
+// ToString converts values to strings using their default format.
+// A nil value is converted to an empty string.
+func ToString() func(interface{}) (interface{}, error) {
+	return describe(func(v interface{}) (interface{}, error) {
+		switch v := v.(type) {
+		case nil:
+			return "", nil
+		case string:
+			return v, nil
+		case []byte:
+			return string(v), nil
+		}
+		return fmt.Sprintf("%v", v), nil
+	}, "ToString()")
+}
+
+// ToInt converts strings, integers, whole floats and bools to an int.
+func ToInt() func(interface{}) (interface{}, error) {
+	return describe(func(v interface{}) (interface{}, error) {
+		switch v := v.(type) {
+		case string:
+			i, err := strconv.Atoi(strings.TrimSpace(v))
+			if err != nil {
+				return nil, fmt.Errorf("failed to convert %q to int", v)
+			}
+			return i, nil
+		case bool:
+			if v {
+				return 1, nil
+			}
+			return 0, nil
+		case float32, float64:
+			f := reflect.ValueOf(v).Float()
+			if f != math.Trunc(f) {
+				return nil, fmt.Errorf("failed to convert %v to int, not a whole number", v)
+			}
+			// math.MaxInt64 rounds up to 1<<63 as a float, which is out of range
+			if f >= math.MaxInt64 || f < math.MinInt64 {
+				return nil, fmt.Errorf("failed to convert %v to int, out of range", v)
+			}
+			return int(f), nil
+		}
+		i, err := toInt64(v)
+		if err != nil {
+			return nil, err
+		}
+		return int(i), nil
+	}, "ToInt()")
+}
+
+// ToFloat converts strings, numbers and bools to a float64.
+func ToFloat() func(interface{}) (interface{}, error) {
+	return describe(func(v interface{}) (interface{}, error) {
+		switch v := v.(type) {
+		case string:
+			f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+			if err != nil {
+				return nil, fmt.Errorf("failed to convert %q to float", v)
+			}
+			return f, nil
+		case bool:
+			if v {
+				return 1.0, nil
+			}
+			return 0.0, nil
+		}
+		f, ok := toFloat64(v)
+		if !ok {
+			return nil, fmt.Errorf("value has invalid type %T, expected a number, string or bool", v)
+		}
+		return f, nil
+	}, "ToFloat()")
+}
+
+// ToBool converts strings (as accepted by strconv.ParseBool) and
+// numbers (non zero is true) to a bool.
+func ToBool() func(interface{}) (interface{}, error) {
+	return describe(func(v interface{}) (interface{}, error) {
+		switch v := v.(type) {
+		case bool:
+			return v, nil
+		case string:
+			b, err := strconv.ParseBool(strings.TrimSpace(v))
+			if err != nil {
+				return nil, fmt.Errorf("failed to convert %q to bool", v)
+			}
+			return b, nil
+		}
+		f, ok := toFloat64(v)
+		if !ok {
+			return nil, fmt.Errorf("value has invalid type %T, expected a number, string or bool", v)
+		}
+		return f != 0, nil
+	}, "ToBool()")
+}
+
+// TrimSpace removes leading and trailing white space from strings.
+func TrimSpace() func(interface{}) (interface{}, error) {
+	return describe(stringFunc(strings.TrimSpace), "TrimSpace()")
+}
+
+// Trim removes leading and trailing characters contained in cutset from strings.
+func Trim(cutset string) func(interface{}) (interface{}, error) {
+	return describe(stringFunc(func(s string) string { return strings.Trim(s, cutset) }), "Trim(%q)", cutset)
+}
+
+// ToUpper converts strings to upper case.
+func ToUpper() func(interface{}) (interface{}, error) {
+	return describe(stringFunc(strings.ToUpper), "ToUpper()")
+}
+
+// ToLower converts strings to lower case.
+func ToLower() func(interface{}) (interface{}, error) {
+	return describe(stringFunc(strings.ToLower), "ToLower()")
+}
+
+// Default replaces a nil value with value.
+func Default(value interface{}) func(interface{}) (interface{}, error) {
+	return describe(func(v interface{}) (interface{}, error) {
+		if v == nil {
+			return value, nil
+		}
+		return v, nil
+	}, "Default(%#v)", value)
+}
+
+// DefaultIfEmpty replaces a nil value or empty string with value.
+func DefaultIfEmpty(value interface{}) func(interface{}) (interface{}, error) {
+	return describe(func(v interface{}) (interface{}, error) {
+		if v == nil || v == "" {
+			return value, nil
+		}
+		return v, nil
+	}, "DefaultIfEmpty(%#v)", value)
+}
+
+// ReplaceRegexp replaces matches of re in strings with repl, which may
+// refer to submatches as for regexp.Regexp.ReplaceAllString.
+func ReplaceRegexp(re *regexp.Regexp, repl string) func(interface{}) (interface{}, error) {
+	return describe(stringFunc(func(s string) string { return re.ReplaceAllString(s, repl) }), "ReplaceRegexp(%q, %q)", re, repl)
+}
+
+// ExtractRegexp returns the given submatch group (0 for the whole match)
+// of the first match of re in strings. It is an error if there is no match.
+func ExtractRegexp(re *regexp.Regexp, group int) func(interface{}) (interface{}, error) {
+	return describe(func(v interface{}) (interface{}, error) {
+		s, ok := v.(string)
+		if !ok {
+			return nil, invalidType(v)
+		}
+		match := re.FindStringSubmatch(s)
+		if match == nil {
+			return nil, fmt.Errorf("%q does not match %s", s, re)
+		}
+		if group < 0 || group >= len(match) {
+			return nil, fmt.Errorf("invalid group %d, %s only has %d groups", group, re, len(match)-1)
+		}
+		return match[group], nil
+	}, "ExtractRegexp(%q, %d)", re, group)
+}
+
+// Substring returns up to length characters of strings starting at
+// character start (from 0). A negative length returns the rest of the string.
+func Substring(start int, length int) func(interface{}) (interface{}, error) {
+	return describe(func(v interface{}) (interface{}, error) {
+		s, ok := v.(string)
+		if !ok {
+			return nil, invalidType(v)
+		}
+		if start < 0 {
+			return nil, fmt.Errorf("invalid start %d", start)
+		}
+		runes := []rune(s)
+		if start >= len(runes) {
+			return "", nil
+		}
+		end := len(runes)
+		if length >= 0 && start+length < end {
+			end = start + length
+		}
+		return string(runes[start:end]), nil
+	}, "Substring(%d, %d)", start, length)
+}
+
+// PadLeft pads strings on the left with pad to at least width characters.
+func PadLeft(width int, pad rune) func(interface{}) (interface{}, error) {
+	return describe(stringFunc(func(s string) string {
+		if n := width - utf8.RuneCountInString(s); n > 0 {
+			return strings.Repeat(string(pad), n) + s
+		}
+		return s
+	}), "PadLeft(%d, %q)", width, pad)
+}
+
+// PadRight pads strings on the right with pad to at least width characters.
+func PadRight(width int, pad rune) func(interface{}) (interface{}, error) {
+	return describe(stringFunc(func(s string) string {
+		if n := width - utf8.RuneCountInString(s); n > 0 {
+			return s + strings.Repeat(string(pad), n)
+		}
+		return s
+	}), "PadRight(%d, %q)", width, pad)
+}
+
+// FormatNumber formats numbers, or strings containing numbers, with the
+// given number of decimal places.
+func FormatNumber(decimals int) func(interface{}) (interface{}, error) {
+	toFloat := ToFloat()
+	return describe(func(v interface{}) (interface{}, error) {
+		if _, ok := v.(bool); ok {
+			return nil, fmt.Errorf("value has invalid type %T, expected a number or string", v)
+		}
+		f, err := toFloat(v)
+		if err != nil {
+			return nil, err
+		}
+		return strconv.FormatFloat(f.(float64), 'f', decimals, 64), nil
+	}, "FormatNumber(%d)", decimals)
+}
+
+// OneOf returns an error unless the value equals one of values.
+func OneOf(values ...interface{}) func(interface{}) (interface{}, error) {
+	return describe(func(v interface{}) (interface{}, error) {
+		for _, value := range values {
+			if reflect.DeepEqual(v, value) {
+				return v, nil
+			}
+		}
+		return nil, fmt.Errorf("%#v is not one of %v", v, values)
+	}, "OneOf(%s)", describeArgs(values))
+}
+
+// describe returns f described in lineage as the call of its constructor
+func describe(f func(interface{}) (interface{}, error), format string, args ...interface{}) func(interface{}) (interface{}, error) {
+	return mapjitsu.Describe("transforms."+fmt.Sprintf(format, args...), f)
+}
+
+func describeArgs(args []interface{}) string {
+	s := make([]string, len(args))
+	for i, arg := range args {
+		s[i] = fmt.Sprintf("%#v", arg)
+	}
+	return strings.Join(s, ", ")
+}
+
+func stringFunc(f func(string) string) func(interface{}) (interface{}, error) {
+	return func(v interface{}) (interface{}, error) {
+		s, ok := v.(string)
+		if !ok {
+			return nil, invalidType(v)
+		}
+		return f(s), nil
+	}
+}
+
+func invalidType(v interface{}) error {
+	return fmt.Errorf("value has invalid type %T, expected string", v)
+}
+
+func toInt64(v interface{}) (int64, error) {
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rv.Int(), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		u := rv.Uint()
+		if u > math.MaxInt64 {
+			return 0, fmt.Errorf("failed to convert %v to int, out of range", v)
+		}
+		return int64(u), nil
+	}
+	return 0, fmt.Errorf("value has invalid type %T, expected a number, string or bool", v)
+}
+
+func toFloat64(v interface{}) (float64, bool) {
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(rv.Uint()), true
+	}
+	return 0, false
+}
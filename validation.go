@@ -0,0 +1,125 @@
+package mapjitsu
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Validator checks a value, returning an error describing why the value
+// is invalid. Rule names the check in a ValidationReport.
+type Validator struct {
+	Rule  string
+	Check func(v interface{}) error
+}
+
+// ValidationFailure records a value which failed a Validator.
+type ValidationFailure struct {
+	Index   int
+	Field   string // the Mapping Name, or a description of its Target
+	Rule    string
+	Value   interface{}
+	Message string
+}
+
+// ValidationReport lists every ValidationFailure found while applying a
+// Definition. It is returned from Apply as an error, alongside any other
+// errors when CollectErrors is set or the error which stopped Apply.
+type ValidationReport struct {
+	Failures []ValidationFailure
+}
+
+func (r *ValidationReport) Error() string {
+	var b strings.Builder
+	b.WriteString("validation failed with ")
+	b.WriteString(strconv.Itoa(len(r.Failures)))
+	b.WriteString(" failures:")
+	for _, f := range r.Failures {
+		fmt.Fprintf(&b, "\n\t* %s: %s: %s (value %#v)", f.Field, f.Rule, f.Message, f.Value)
+	}
+	return b.String()
+}
+
+// validationError carries the failures for a single Mapping back to Apply
+type validationError struct {
+	failures []ValidationFailure
+}
+
+func (e *validationError) Error() string {
+	return (&ValidationReport{Failures: e.failures}).Error()
+}
+
+// validate runs every Validator against v
+func (m Mapping) validate(index int, v interface{}) error {
+	var failures []ValidationFailure
+	for _, validator := range m.Validate {
+		err := validator.Check(v)
+		if err != nil {
+			failures = append(failures, ValidationFailure{
+				Index:   index,
+				Field:   m.field(index),
+				Rule:    validator.Rule,
+				Value:   v,
+				Message: err.Error(),
+			})
+		}
+	}
+	if len(failures) > 0 {
+		return &validationError{failures: failures}
+	}
+	return nil
+}
+
+func (m Mapping) field(index int) string {
+	if m.Name != "" {
+		return m.Name
+	}
+	if s, ok := m.Target.(fmt.Stringer); ok {
+		return s.String()
+	}
+	return "mapping " + strconv.Itoa(index)
+}
+
+// result combines the errors from applying each Mapping, merging any
+// validation failures into a single ValidationReport. The first error
+// which is not collected stopped the Definition, it is returned alone or
+// together with any ValidationReport and errors collected before it.
+func (d Definition) result(errs []error) error {
+	var report ValidationReport
+	var collected Errors
+	var failed error
+	for _, err := range errs {
+		if err == nil {
+			continue
+		}
+		if v, ok := err.(*validationError); ok {
+			report.Failures = append(report.Failures, v.failures...)
+			continue
+		}
+		if failed != nil {
+			continue // concurrent Mappings which failed after the Definition stopped
+		}
+		if !d.collects(err) {
+			failed = err
+		}
+		collected = append(collected, err)
+	}
+	if failed != nil && len(collected) == 1 && len(report.Failures) == 0 {
+		return failed
+	}
+	if len(report.Failures) > 0 {
+		if len(collected) == 0 {
+			return &report
+		}
+		collected = append(collected, &report)
+	}
+	if len(collected) > 0 {
+		return collected
+	}
+	return nil
+}
+
+func isValidationError(err error) bool {
+	_, ok := err.(*validationError)
+	return ok
+}
@@ -0,0 +1,202 @@
+// Package validators provides constructors for common mapjitsu Validators e.g.
+//
+//	Validate: []mapjitsu.Validator{validators.Required(), validators.Length(1, 50)}
+//
+// Apart from Required and NotEmpty, validators accept nil values so they can
+// be used with optional fields.
+package validators
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/8legd/mapjitsu"
+)
+
+// Required fails for nil values.
+func Required() mapjitsu.Validator {
+	return mapjitsu.Validator{
+		Rule: "required",
+		Check: func(v interface{}) error {
+			if v == nil {
+				return fmt.Errorf("value is required")
+			}
+			return nil
+		},
+	}
+}
+
+// NotEmpty fails for nil values, empty or blank strings and empty
+// slices or maps.
+func NotEmpty() mapjitsu.Validator {
+	return mapjitsu.Validator{
+		Rule: "not-empty",
+		Check: func(v interface{}) error {
+			if v == nil {
+				return fmt.Errorf("value is required")
+			}
+			if s, ok := v.(string); ok {
+				if strings.TrimSpace(s) == "" {
+					return fmt.Errorf("value is empty")
+				}
+				return nil
+			}
+			switch rv := reflect.ValueOf(v); rv.Kind() {
+			case reflect.Slice, reflect.Map, reflect.Array:
+				if rv.Len() == 0 {
+					return fmt.Errorf("value is empty")
+				}
+			}
+			return nil
+		},
+	}
+}
+
+// Regexp fails for strings which do not match re.
+func Regexp(re *regexp.Regexp) mapjitsu.Validator {
+	return mapjitsu.Validator{
+		Rule: "regexp",
+		Check: func(v interface{}) error {
+			if v == nil {
+				return nil
+			}
+			s, ok := v.(string)
+			if !ok {
+				return fmt.Errorf("value has invalid type %T, expected string", v)
+			}
+			if !re.MatchString(s) {
+				return fmt.Errorf("value does not match %s", re)
+			}
+			return nil
+		},
+	}
+}
+
+// Range fails for numbers, or strings containing numbers, outside of
+// min to max inclusive.
+func Range(min float64, max float64) mapjitsu.Validator {
+	return mapjitsu.Validator{
+		Rule: "range",
+		Check: func(v interface{}) error {
+			if v == nil {
+				return nil
+			}
+			f, err := toFloat64(v)
+			if err != nil {
+				return err
+			}
+			if math.IsNaN(f) || f < min || f > max {
+				return fmt.Errorf("value is not in the range %v to %v", min, max)
+			}
+			return nil
+		},
+	}
+}
+
+// Length fails for strings (counting characters), slices and maps with
+// a length outside of min to max inclusive. A negative max is unlimited.
+func Length(min int, max int) mapjitsu.Validator {
+	return mapjitsu.Validator{
+		Rule: "length",
+		Check: func(v interface{}) error {
+			if v == nil {
+				return nil
+			}
+			var n int
+			if s, ok := v.(string); ok {
+				n = utf8.RuneCountInString(s)
+			} else {
+				switch rv := reflect.ValueOf(v); rv.Kind() {
+				case reflect.Slice, reflect.Map, reflect.Array:
+					n = rv.Len()
+				default:
+					return fmt.Errorf("value has invalid type %T, expected string, slice or map", v)
+				}
+			}
+			if n < min {
+				return fmt.Errorf("length %d is less than %d", n, min)
+			}
+			if max >= 0 && n > max {
+				return fmt.Errorf("length %d is greater than %d", n, max)
+			}
+			return nil
+		},
+	}
+}
+
+// Enum fails for values not equal to one of values.
+func Enum(values ...interface{}) mapjitsu.Validator {
+	return mapjitsu.Validator{
+		Rule: "enum",
+		Check: func(v interface{}) error {
+			if v == nil {
+				return nil
+			}
+			for _, value := range values {
+				if reflect.DeepEqual(v, value) {
+					return nil
+				}
+			}
+			return fmt.Errorf("value is not one of %v", values)
+		},
+	}
+}
+
+// DateRange fails for dates outside of from to to inclusive. Values may
+// be a time.Time or a string in the given layout (see time.Parse).
+// A zero from or to leaves that end of the range open.
+func DateRange(layout string, from time.Time, to time.Time) mapjitsu.Validator {
+	return mapjitsu.Validator{
+		Rule: "date-range",
+		Check: func(v interface{}) error {
+			var t time.Time
+			switch v := v.(type) {
+			case nil:
+				return nil
+			case time.Time:
+				t = v
+			case string:
+				var err error
+				t, err = time.Parse(layout, v)
+				if err != nil {
+					return fmt.Errorf("value is not a date in the format %s", layout)
+				}
+			default:
+				return fmt.Errorf("value has invalid type %T, expected string or time.Time", v)
+			}
+			if !from.IsZero() && t.Before(from) {
+				return fmt.Errorf("date is before %s", from.Format(layout))
+			}
+			if !to.IsZero() && t.After(to) {
+				return fmt.Errorf("date is after %s", to.Format(layout))
+			}
+			return nil
+		},
+	}
+}
+
+func toFloat64(v interface{}) (float64, error) {
+	if s, ok := v.(string); ok {
+		f, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+		if err != nil {
+			return 0, fmt.Errorf("value is not a number")
+		}
+		return f, nil
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(rv.Uint()), nil
+	}
+	return 0, fmt.Errorf("value has invalid type %T, expected a number", v)
+}
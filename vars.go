@@ -0,0 +1,259 @@
+package mapjitsu
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Var is a named intermediate variable which can be used as both a
+// Target and a Source, allowing Mappings to consume the results of
+// other Mappings. Variables only exist for the duration of an Apply and
+// a Definition orders its Mappings so variables are written before
+// they are read.
+type Var string
+
+// Value returns an error as variables can only be read during Apply.
+func (v Var) Value() (interface{}, error) {
+	return nil, fmt.Errorf("variable %s can only be read during Apply", string(v))
+}
+
+// ValueContext returns the value of the variable.
+func (v Var) ValueContext(ctx context.Context) (interface{}, error) {
+	s, ok := ctx.Value(varsKey{}).(*varStore)
+	if !ok {
+		return v.Value()
+	}
+	return s.get(string(v))
+}
+
+// SetValue returns an error as variables can only be written during Apply.
+func (v Var) SetValue(interface{}) error {
+	return fmt.Errorf("variable %s can only be written during Apply", string(v))
+}
+
+// SetValueContext sets the value of the variable.
+func (v Var) SetValueContext(ctx context.Context, value interface{}) error {
+	s, ok := ctx.Value(varsKey{}).(*varStore)
+	if !ok {
+		return v.SetValue(value)
+	}
+	s.set(string(v), value)
+	return nil
+}
+
+func (v Var) String() string {
+	return "var " + string(v)
+}
+
+// ReadsVars returns the variable read when v is used as a Source.
+func (v Var) ReadsVars() []string {
+	return []string{string(v)}
+}
+
+// WritesVars returns the variable written when v is used as a Target.
+func (v Var) WritesVars() []string {
+	return []string{string(v)}
+}
+
+// Computed is a Source which calculates its value from one or more
+// variables, e.g. combining cleaned first and last names into a full name.
+type Computed struct {
+	Vars []string
+	Func func(vars map[string]interface{}) (interface{}, error)
+}
+
+// Value returns an error as variables can only be read during Apply.
+func (c Computed) Value() (interface{}, error) {
+	return nil, fmt.Errorf("variables %s can only be read during Apply", strings.Join(c.Vars, ", "))
+}
+
+// ValueContext returns the result of calling Func with the values of Vars.
+func (c Computed) ValueContext(ctx context.Context) (interface{}, error) {
+	s, ok := ctx.Value(varsKey{}).(*varStore)
+	if !ok {
+		return c.Value()
+	}
+	vars := make(map[string]interface{}, len(c.Vars))
+	for _, name := range c.Vars {
+		v, err := s.get(name)
+		if err != nil {
+			return nil, err
+		}
+		vars[name] = v
+	}
+	return c.Func(vars)
+}
+
+func (c Computed) String() string {
+	return "computed from var " + strings.Join(c.Vars, ", ")
+}
+
+// ReadsVars returns Vars.
+func (c Computed) ReadsVars() []string {
+	return c.Vars
+}
+
+// VarReader is implemented by Sources which read variables.
+type VarReader interface {
+	ReadsVars() []string
+}
+
+// VarWriter is implemented by Targets which write variables.
+type VarWriter interface {
+	WritesVars() []string
+}
+
+// CycleError is returned when Mappings depend on each other's variables,
+// listing the Mappings which could not be ordered.
+type CycleError struct {
+	Mappings []int
+}
+
+func (e *CycleError) Error() string {
+	indices := make([]string, len(e.Mappings))
+	for i, index := range e.Mappings {
+		indices[i] = strconv.Itoa(index)
+	}
+	return "variable dependency cycle involving mappings " + strings.Join(indices, ", ")
+}
+
+type varsKey struct{}
+
+type varStore struct {
+	mu     sync.RWMutex
+	values map[string]interface{}
+}
+
+func withVars(ctx context.Context) context.Context {
+	return context.WithValue(ctx, varsKey{}, &varStore{values: make(map[string]interface{})})
+}
+
+func (s *varStore) get(name string) (interface{}, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	v, ok := s.values[name]
+	if !ok {
+		return nil, NotFound(fmt.Errorf("variable %s has not been set", name))
+	}
+	return v, nil
+}
+
+func (s *varStore) set(name string, v interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.values[name] = v
+}
+
+// dependencies returns, for each Mapping, the indices of the Mappings
+// writing the variables it reads.
+func (d Definition) dependencies() ([][]int, error) {
+	writers := make(map[string][]int)
+	for i, m := range d.Mappings {
+		if w, ok := m.Target.(VarWriter); ok {
+			for _, name := range w.WritesVars() {
+				writers[name] = append(writers[name], i)
+			}
+		}
+	}
+	deps := make([][]int, len(d.Mappings))
+	for i, m := range d.Mappings {
+		sources := []struct {
+			stage  Stage
+			source Source
+		}{{StageSource, m.Source}, {StageCondition, m.WhenSource}}
+		for _, s := range sources {
+			r, ok := s.source.(VarReader)
+			if !ok {
+				continue
+			}
+			for _, name := range r.ReadsVars() {
+				w, ok := writers[name]
+				if !ok {
+					return nil, &MappingError{Index: i, Name: m.Name, Stage: s.stage,
+						Err: fmt.Errorf("variable %s is not written by any mapping", name)}
+				}
+				deps[i] = append(deps[i], w...)
+			}
+		}
+	}
+	return deps, nil
+}
+
+// levels groups the Mappings so each depends only on Mappings in
+// earlier levels, keeping Definition order within a level.
+func (d Definition) levels() ([][]int, error) {
+	deps, err := d.dependencies()
+	if err != nil {
+		return nil, err
+	}
+	level := make([]int, len(d.Mappings))
+	for i := range level {
+		level[i] = -1
+	}
+	var levels [][]int
+	for placed := 0; placed < len(d.Mappings); {
+		var next []int
+		for i := range d.Mappings {
+			if level[i] < 0 && ready(deps[i], func(dep int) bool { return level[dep] >= 0 }) {
+				next = append(next, i)
+			}
+		}
+		if len(next) == 0 {
+			return nil, d.cycle(func(i int) bool { return level[i] < 0 })
+		}
+		for _, i := range next {
+			level[i] = len(levels)
+		}
+		placed += len(next)
+		levels = append(levels, next)
+	}
+	return levels, nil
+}
+
+// order returns the indices of the Mappings in the order they should be
+// applied, keeping as close to Definition order as dependencies allow.
+func (d Definition) order() ([]int, error) {
+	deps, err := d.dependencies()
+	if err != nil {
+		return nil, err
+	}
+	done := make([]bool, len(d.Mappings))
+	order := make([]int, 0, len(d.Mappings))
+	for len(order) < len(d.Mappings) {
+		next := -1
+		for i := range d.Mappings {
+			if !done[i] && ready(deps[i], func(dep int) bool { return done[dep] }) {
+				next = i
+				break
+			}
+		}
+		if next < 0 {
+			return nil, d.cycle(func(i int) bool { return !done[i] })
+		}
+		done[next] = true
+		order = append(order, next)
+	}
+	return order, nil
+}
+
+func ready(deps []int, done func(int) bool) bool {
+	for _, dep := range deps {
+		if !done(dep) {
+			return false
+		}
+	}
+	return true
+}
+
+func (d Definition) cycle(unplaced func(int) bool) *CycleError {
+	var cycle []int
+	for i := range d.Mappings {
+		if unplaced(i) {
+			cycle = append(cycle, i)
+		}
+	}
+	return &CycleError{Mappings: cycle}
+}